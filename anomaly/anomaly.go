@@ -0,0 +1,189 @@
+package anomaly
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"webscraping/common"
+
+	"github.com/rs/zerolog"
+)
+
+// Métodos de detección
+const (
+	MethodZScore  = "zscore"
+	MethodPercent = "percent"
+)
+
+// Qué hacer con los valores anómalos
+const (
+	ActionReport  = "report"
+	ActionExclude = "exclude"
+	ActionFail    = "fail"
+)
+
+type Config struct {
+	Enabled bool   `json:"enabled" yaml:"enabled"`
+	Method  string `json:"method" yaml:"method"`
+	// Desviaciones estándar para zscore, porcentaje de cambio para percent
+	Threshold float64 `json:"threshold" yaml:"threshold"`
+	// Corridas anteriores que se usan para la media y desviación
+	Window int `json:"window" yaml:"window"`
+	// Mínimo de corridas anteriores para poder evaluar con zscore
+	MinHistory int `json:"min_history" yaml:"min_history"`
+	// Valor mínimo, en la corrida actual o en alguna anterior, para evaluar un nombre. Los tags con pocas
+	// menciones cambian mucho de una corrida a otra sin que sea una anomalía.
+	MinCount float64 `json:"min_count" yaml:"min_count"`
+	Action   string  `json:"action" yaml:"action"`
+}
+
+type Anomaly struct {
+	Name     string
+	Value    float64
+	Expected float64
+	// Valor z o cambio porcentual según el método
+	Score float64
+}
+
+type Detector struct {
+	logger zerolog.Logger
+	config Config
+}
+
+func GetDefaultConfig() Config {
+	return Config{
+		Enabled:    true,
+		Method:     MethodPercent,
+		Threshold:  70,
+		Window:     10,
+		MinHistory: 3,
+		MinCount:   20,
+		Action:     ActionReport,
+	}
+}
+
+func NewDetector(config Config, logger zerolog.Logger) (*Detector, error) {
+	switch config.Method {
+	case MethodZScore, MethodPercent:
+	default:
+		return nil, common.NewConfigError("anomalies.method", config.Method)
+	}
+	switch config.Action {
+	case ActionReport, ActionExclude, ActionFail:
+	default:
+		return nil, common.NewConfigError("anomalies.action", config.Action)
+	}
+	return &Detector{logger: logger.With().Str("struct", "Detector").Logger(), config: config}, nil
+}
+
+// Compara los valores actuales con las corridas anteriores, ordenadas de la más antigua a la más reciente.
+// Un nombre de la ventana de historial que no está en current se evalúa con valor 0, así se reporta lo que desaparece.
+func (d *Detector) Detect(current map[string]float64, previous []map[string]float64) []Anomaly {
+	l := d.logger.With().Str("method", "Detect").Logger()
+
+	if d.config.Window > 0 && len(previous) > d.config.Window {
+		previous = previous[len(previous)-d.config.Window:]
+	}
+
+	values := make(map[string]float64, len(current))
+	for name, value := range current {
+		values[name] = value
+	}
+	for _, run := range previous {
+		for name := range run {
+			if _, ok := values[name]; !ok {
+				l.Trace().Str("nombre", name).Msg("Ausente en la corrida actual, evaluando como 0")
+				values[name] = 0
+			}
+		}
+	}
+
+	var anomalies []Anomaly
+	for name, value := range values {
+		var series []float64
+		largest := value
+		for _, run := range previous {
+			if v, ok := run[name]; ok {
+				series = append(series, v)
+				largest = math.Max(largest, v)
+			}
+		}
+		if len(series) == 0 {
+			l.Trace().Str("nombre", name).Msg("Sin historial, no se puede evaluar")
+			continue
+		}
+		if largest < d.config.MinCount {
+			l.Trace().Str("nombre", name).Msg("Valores menores a min_count, no se evalúa")
+			continue
+		}
+
+		var anomaly *Anomaly
+		if d.config.Method == MethodZScore {
+			anomaly = d.zscore(name, value, series)
+		} else {
+			anomaly = d.percent(name, value, series[len(series)-1])
+		}
+		if anomaly != nil {
+			anomalies = append(anomalies, *anomaly)
+		}
+	}
+	sort.Slice(anomalies, func(i, j int) bool { return math.Abs(anomalies[i].Score) > math.Abs(anomalies[j].Score) })
+	return anomalies
+}
+
+func (d *Detector) zscore(name string, value float64, series []float64) *Anomaly {
+	if len(series) < d.config.MinHistory {
+		return nil
+	}
+	var mean, variance float64
+	for _, v := range series {
+		mean += v
+	}
+	mean /= float64(len(series))
+	for _, v := range series {
+		variance += (v - mean) * (v - mean)
+	}
+	stddev := math.Sqrt(variance / float64(len(series)))
+	if stddev == 0 {
+		// Con un historial constante cualquier cambio está a infinitas desviaciones
+		if value == mean {
+			return nil
+		}
+		return &Anomaly{Name: name, Value: value, Expected: mean, Score: math.Copysign(math.Inf(1), value-mean)}
+	}
+	z := (value - mean) / stddev
+	if math.Abs(z) <= d.config.Threshold {
+		return nil
+	}
+	return &Anomaly{Name: name, Value: value, Expected: mean, Score: z}
+}
+
+func (d *Detector) percent(name string, value, last float64) *Anomaly {
+	if last == 0 {
+		return nil
+	}
+	change := (value - last) / last * 100
+	if math.Abs(change) <= d.config.Threshold {
+		return nil
+	}
+	return &Anomaly{Name: name, Value: value, Expected: last, Score: change}
+}
+
+func (a *Anomaly) String() string {
+	if a == nil {
+		return ""
+	}
+	return fmt.Sprintf("%30s, %12.2f, %12.2f, %+10.2f", a.Name, a.Value, a.Expected, a.Score)
+}
+
+func Report(method string, anomalies []Anomaly) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Valores anómalos (%v):\n", method))
+	sb.WriteString(fmt.Sprintf("%30s, %12s, %12s, %10s\n", "nombre", "valor", "esperado", method))
+	for _, a := range anomalies {
+		sb.WriteString(a.String())
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
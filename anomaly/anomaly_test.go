@@ -0,0 +1,147 @@
+package anomaly
+
+import (
+	"math"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func detector(t *testing.T, method string, threshold float64) *Detector {
+	config := GetDefaultConfig()
+	config.Method = method
+	config.Threshold = threshold
+	d, err := NewDetector(config, zerolog.Nop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func find(anomalies []Anomaly, name string) *Anomaly {
+	for i := range anomalies {
+		if anomalies[i].Name == name {
+			return &anomalies[i]
+		}
+	}
+	return nil
+}
+
+func TestZScoreConstantHistory(t *testing.T) {
+	d := detector(t, MethodZScore, 3)
+	previous := []map[string]float64{{"go": 1000, "c": 500}, {"go": 1000, "c": 500}, {"go": 1000, "c": 500}}
+
+	anomalies := d.Detect(map[string]float64{"go": 100, "c": 500}, previous)
+	if len(anomalies) != 1 {
+		t.Fatalf("got %v anomalies, want 1: %v", len(anomalies), anomalies)
+	}
+	a := anomalies[0]
+	if a.Name != "go" || a.Expected != 1000 || !math.IsInf(a.Score, -1) {
+		t.Errorf("got %+v, want go with expected 1000 and score -Inf", a)
+	}
+}
+
+func TestZScore(t *testing.T) {
+	d := detector(t, MethodZScore, 2)
+	// media 100, desviación 10
+	previous := []map[string]float64{{"go": 90}, {"go": 110}, {"go": 90}, {"go": 110}}
+
+	if anomalies := d.Detect(map[string]float64{"go": 115}, previous); len(anomalies) != 0 {
+		t.Errorf("z = 1.5 should not be anomalous: %v", anomalies)
+	}
+	anomalies := d.Detect(map[string]float64{"go": 130}, previous)
+	if len(anomalies) != 1 || math.Abs(anomalies[0].Score-3) > 1e-9 {
+		t.Errorf("got %v, want z = 3", anomalies)
+	}
+}
+
+func TestZScoreMinHistory(t *testing.T) {
+	d := detector(t, MethodZScore, 1)
+	previous := []map[string]float64{{"go": 1000}, {"go": 1000}}
+	if anomalies := d.Detect(map[string]float64{"go": 1}, previous); len(anomalies) != 0 {
+		t.Errorf("fewer than min_history runs should not be evaluated: %v", anomalies)
+	}
+}
+
+func TestPercent(t *testing.T) {
+	d := detector(t, MethodPercent, 50)
+	previous := []map[string]float64{{"go": 10, "c": 100}, {"go": 100, "c": 100}}
+
+	anomalies := d.Detect(map[string]float64{"go": 40, "c": 120}, previous)
+	if len(anomalies) != 1 {
+		t.Fatalf("got %v, want only go", anomalies)
+	}
+	if a := anomalies[0]; a.Name != "go" || a.Expected != 100 || a.Score != -60 {
+		t.Errorf("got %+v, want go expected 100 and -60%%", a)
+	}
+}
+
+func TestMissingNames(t *testing.T) {
+	tests := []struct {
+		method    string
+		threshold float64
+	}{
+		{MethodPercent, 70},
+		{MethodZScore, 3},
+	}
+	for _, test := range tests {
+		t.Run(test.method, func(t *testing.T) {
+			d := detector(t, test.method, test.threshold)
+			previous := []map[string]float64{{"go": 100, "rust": 50}, {"go": 100, "rust": 52}, {"go": 100, "rust": 51}}
+
+			anomalies := d.Detect(map[string]float64{"go": 100}, previous)
+			a := find(anomalies, "rust")
+			if a == nil || a.Value != 0 {
+				t.Fatalf("got %v, want rust reported with value 0", anomalies)
+			}
+			if find(anomalies, "go") != nil {
+				t.Errorf("go did not change: %v", anomalies)
+			}
+		})
+	}
+}
+
+func TestWindow(t *testing.T) {
+	config := GetDefaultConfig()
+	config.Method = MethodPercent
+	config.Window = 1
+	d, err := NewDetector(config, zerolog.Nop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// rust solo aparece fuera de la ventana
+	previous := []map[string]float64{{"go": 100, "rust": 50}, {"go": 100}}
+	if anomalies := d.Detect(map[string]float64{"go": 100}, previous); len(anomalies) != 0 {
+		t.Errorf("names outside the window should be ignored: %v", anomalies)
+	}
+}
+
+func TestNewDetectorInvalid(t *testing.T) {
+	config := GetDefaultConfig()
+	config.Method = "iqr"
+	if _, err := NewDetector(config, zerolog.Nop()); err == nil {
+		t.Error("expected error for unknown method")
+	}
+	config = GetDefaultConfig()
+	config.Action = "ignore"
+	if _, err := NewDetector(config, zerolog.Nop()); err == nil {
+		t.Error("expected error for unknown action")
+	}
+}
+
+func TestMinCount(t *testing.T) {
+	d := detector(t, MethodPercent, 70)
+	previous := []map[string]float64{{"go": 1000, "cli": 3, "wasm": 15}}
+
+	// cli pasa de 3 a 10 (+233%) pero nunca llega a min_count; wasm llega en la corrida actual
+	anomalies := d.Detect(map[string]float64{"go": 1100, "cli": 10, "wasm": 40}, previous)
+	if len(anomalies) != 1 || anomalies[0].Name != "wasm" {
+		t.Errorf("got %v, want only wasm", anomalies)
+	}
+
+	// Un valor que cae por debajo de min_count se evalúa si antes lo superaba
+	anomalies = d.Detect(map[string]float64{"go": 1000, "cli": 3, "wasm": 15}, []map[string]float64{{"go": 1000, "cli": 3, "wasm": 60}})
+	if len(anomalies) != 1 || anomalies[0].Name != "wasm" {
+		t.Errorf("got %v, want only wasm", anomalies)
+	}
+}
@@ -1,27 +1,74 @@
 package app
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime"
+	"strings"
+	"time"
+	"webscraping/anomaly"
+	"webscraping/common"
+	"webscraping/daemon"
+	"webscraping/dashboard"
+	"webscraping/export"
 	"webscraping/fileconfig"
+	"webscraping/forecast"
+	"webscraping/history"
+	"webscraping/metrics"
+	"webscraping/notify"
+	"webscraping/resultproc"
 	"webscraping/scraping"
+	"webscraping/server"
+	"webscraping/staticchart"
+	"webscraping/terminal"
 
 	"github.com/rs/zerolog"
+	"gopkg.in/yaml.v3"
 )
 
 type Application struct {
-	ConfigFile *string
-	Logger     zerolog.Logger
-	Config     ApplicationConfig
+	// Archivos de configuración en orden, cada uno pisa los valores del anterior
+	ConfigFiles []string
+	// Asignaciones ruta=valor de --set, aplicadas después de las variables de entorno
+	Overrides []string
+	Logger    zerolog.Logger
+	Config    ApplicationConfig
+	// Origen de cada valor de la configuración
+	Layers *fileconfig.Layers
+	// nil si las métricas están deshabilitadas
+	Metrics *metrics.Registry
+	// Compartido por todos los comandos y trabajos para que su mutex sirva entre el servidor y las corridas
+	history *history.HistoryStore
 }
 
 type ApplicationConfig struct {
-	UseFixedList bool                   `json:"usar_lista_fija" yaml:"usar_lista_fija"`
-	LangList     []string               `json:"lista_lenguajes" yaml:"lista_lenguajes"`
-	Scraper      scraping.Scraperconfig `json:"scraper" yaml:"scraper"`
-	HtmlFile     string                 `json:"archivo_html_grafo" yaml:"archivo_html_grafo"`
-	ResultFile   string                 `json:"archivo_resultado" yaml:"archivo_resultado"`
+	UseFixedList  bool                       `json:"usar_lista_fija" yaml:"usar_lista_fija"`
+	LangList      []string                   `json:"lista_lenguajes" yaml:"lista_lenguajes"`
+	Scraper       scraping.Scraperconfig     `json:"scraper" yaml:"scraper"`
+	HtmlFile      string                     `json:"archivo_html_grafo" yaml:"archivo_html_grafo"`
+	ScatterHtml   string                     `json:"archivo_html_dispersion" yaml:"archivo_html_dispersion"`
+	ResultFile    string                     `json:"archivo_resultado" yaml:"archivo_resultado"`
+	Formats       []string                   `json:"formatos_exportacion" yaml:"formatos_exportacion"`
+	TableHtml     string                     `json:"archivo_html_tabla_lenguajes" yaml:"archivo_html_tabla_lenguajes"`
+	TableFile     string                     `json:"archivo_csv_tabla_lenguajes" yaml:"archivo_csv_tabla_lenguajes"`
+	Scorer        string                     `json:"algoritmo_puntaje" yaml:"algoritmo_puntaje"`
+	Composite     resultproc.CompositeConfig `json:"composite_index" yaml:"composite_index"`
+	History       history.Config             `json:"history" yaml:"history"`
+	CompareHtml   string                     `json:"archivo_html_comparacion" yaml:"archivo_html_comparacion"`
+	HistoryHtml   string                     `json:"archivo_html_historial" yaml:"archivo_html_historial"`
+	Forecast      forecast.Config            `json:"forecast" yaml:"forecast"`
+	Anomalies     anomaly.Config             `json:"anomalies" yaml:"anomalies"`
+	Chart         resultproc.ChartsConfig    `json:"chart" yaml:"chart"`
+	Static        staticchart.Config         `json:"static_charts" yaml:"static_charts"`
+	Terminal      terminal.Config            `json:"terminal" yaml:"terminal"`
+	Dashboard     dashboard.Config           `json:"dashboard" yaml:"dashboard"`
+	Server        server.Config              `json:"server" yaml:"server"`
+	Daemon        daemon.Config              `json:"daemon" yaml:"daemon"`
+	Metrics       metrics.Config             `json:"metrics" yaml:"metrics"`
+	Notifications notify.Config              `json:"notifications" yaml:"notifications"`
 }
 
 func (app *Application) Configure(loglevelstr string) error {
@@ -43,14 +90,54 @@ func (app *Application) Configure(loglevelstr string) error {
 	app.Config.LangList = []string{}
 	app.Config.UseFixedList = false
 	app.Config.HtmlFile = "grafo.html"
+	app.Config.ScatterHtml = "dispersion.html"
 	app.Config.ResultFile = "resultado.txt"
+	app.Config.Formats = []string{"csv"}
+	app.Config.TableHtml = "tabla_lenguajes.html"
+	app.Config.TableFile = "tabla_lenguajes.csv"
+	app.Config.Scorer = "minmax"
+	app.Config.Composite = resultproc.GetDefaultCompositeConfig()
+	app.Config.History = history.GetDefaultConfig()
+	app.Config.CompareHtml = "comparacion.html"
+	app.Config.HistoryHtml = "historial.html"
+	app.Config.Forecast = forecast.GetDefaultConfig()
+	app.Config.Anomalies = anomaly.GetDefaultConfig()
+	app.Config.Chart = resultproc.GetDefaultChartsConfig()
+	app.Config.Static = staticchart.GetDefaultConfig()
+	app.Config.Terminal = terminal.GetDefaultConfig()
+	app.Config.Dashboard = dashboard.GetDefaultConfig()
+	app.Config.Server = server.GetDefaultConfig()
+	app.Config.Daemon = daemon.GetDefaultConfig()
+	app.Config.Metrics = metrics.GetDefaultConfig()
+	app.Config.Notifications = notify.GetDefaultConfig()
 
-	l.Trace().Msg("Creando fileconfigstore")
-	fs := fileconfig.NewFileConfigstore(l, *app.ConfigFile)
-	l.Trace().Msg("Cargando configuración de archivo")
-	err = fs.Load(&app.Config)
+	l.Trace().Msg("Creando capas de configuración")
+	app.Layers, err = fileconfig.NewLayers(l, &app.Config)
+	if err != nil {
+		return err
+	}
+	for i, file := range app.ConfigFiles {
+		l.Trace().Str("file", file).Msg("Cargando configuración de archivo")
+		if err = app.Layers.File(&app.Config, file, i == 0); err != nil {
+			return err
+		}
+	}
+	l.Trace().Msg("Aplicando variables de entorno")
+	if err = app.Layers.Env(&app.Config, os.Environ()); err != nil {
+		return err
+	}
+	l.Trace().Msg("Aplicando --set")
+	if err = app.Layers.Set(&app.Config, app.Overrides); err != nil {
+		return err
+	}
 
-	return err
+	if app.Config.Metrics.Enabled {
+		l.Trace().Msg("Creando registro de métricas")
+		app.Metrics = metrics.NewRegistry()
+	}
+	l.Trace().Msg("Creando historial")
+	app.history = history.NewHistoryStore(app.Logger, app.Config.History.Directory)
+	return nil
 }
 
 func (app *Application) OpenGraph() error {
@@ -61,9 +148,308 @@ func (app *Application) OpenGraph() error {
 	case "windows":
 		args = []string{"explorer", app.Config.HtmlFile}
 	default:
+		// Sin entorno gráfico (servidores, CI) xdg-open no hace nada
+		if os.Getenv("DISPLAY") == "" && os.Getenv("WAYLAND_DISPLAY") == "" {
+			return common.NewNotFoundError("entorno gráfico para abrir " + app.Config.HtmlFile)
+		}
 		args = []string{"xdg-open", app.Config.HtmlFile}
 	}
 	cmd := exec.Command(args[0], args[1:]...)
 	err := cmd.Run()
 	return err
 }
+
+func (app *Application) ConfigHash() string {
+	content, err := yaml.Marshal(&app.Config)
+	if err != nil {
+		app.Logger.Warn().Err(err).Str("method", "ConfigHash").Msg("No se pudo serializar configuración")
+		return ""
+	}
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+func (app *Application) History() *history.HistoryStore {
+	return app.history
+}
+
+func (app *Application) SaveRun(run *history.Run) error {
+	l := app.Logger.With().Str("struct", "app").Str("method", "SaveRun").Logger()
+
+	if !app.Config.History.Enabled {
+		l.Trace().Msg("Historial deshabilitado")
+		return nil
+	}
+	run.ConfigHash = app.ConfigHash()
+	l.Trace().Str("kind", run.Kind).Msg("Guardando corrida en el historial")
+	return app.History().Append(run)
+}
+
+// Compara el resultado con el historial. Con la acción exclude retorna los nombres a descartar
+// y con fail retorna error si hubo anomalías.
+func (app *Application) CheckAnomalies(kind string, current map[string]float64) ([]anomaly.Anomaly, error) {
+	l := app.Logger.With().Str("struct", "app").Str("method", "CheckAnomalies").Logger()
+
+	if !app.Config.Anomalies.Enabled {
+		l.Trace().Msg("Detección de anomalías deshabilitada")
+		return nil, nil
+	}
+	detector, err := anomaly.NewDetector(app.Config.Anomalies, app.Logger)
+	if err != nil {
+		l.Error().Err(err).Msg("Configuración de anomalías inválida")
+		return nil, err
+	}
+
+	l.Trace().Str("kind", kind).Msg("Leyendo historial")
+	runs, err := app.History().Query(history.Query{Kind: kind})
+	if err != nil {
+		l.Error().Err(err).Msg("No se pudo leer el historial")
+		return nil, err
+	}
+	var previous []map[string]float64
+	for _, run := range runs {
+		previous = append(previous, run.Values())
+	}
+
+	anomalies := detector.Detect(current, previous)
+	for _, a := range anomalies {
+		l.Warn().Str("nombre", a.Name).Float64("valor", a.Value).Float64("esperado", a.Expected).Float64(app.Config.Anomalies.Method, a.Score).Msg("Valor anómalo, posible error de alias o de lectura")
+	}
+	if len(anomalies) > 0 && app.Config.Anomalies.Action == anomaly.ActionFail {
+		return anomalies, common.NewAnomalyError(len(anomalies))
+	}
+	return anomalies, nil
+}
+
+// Metadatos de exportación de una corrida ya guardada en el historial
+func (app *Application) Metadata(run *history.Run) export.Metadata {
+	meta := export.Metadata{
+		RunID:      run.ID,
+		Kind:       run.Kind,
+		Timestamp:  run.Timestamp,
+		ConfigHash: run.ConfigHash,
+	}
+	if meta.Timestamp.IsZero() {
+		meta.Timestamp = time.Now()
+	}
+	if meta.ConfigHash == "" {
+		meta.ConfigHash = app.ConfigHash()
+	}
+	return meta
+}
+
+// Exporta en cada formato configurado. El nombre es el de archivo_resultado con la extensión del formato.
+func (app *Application) Export(table *export.Table) ([]string, error) {
+	base := strings.TrimSuffix(app.Config.ResultFile, filepath.Ext(app.Config.ResultFile))
+	return export.ExportAll(base, app.Config.Formats, table, app.Logger)
+}
+
+// Guarda la versión estática de una gráfica si está habilitada, usando el nombre del html sin extensión
+func (app *Application) StaticGraph(htmlfile string, graph func(base string, static staticchart.Config) ([]string, error)) {
+	l := app.Logger.With().Str("struct", "app").Str("method", "StaticGraph").Logger()
+
+	if !app.Config.Static.Enabled {
+		l.Trace().Msg("Gráficas estáticas deshabilitadas")
+		return
+	}
+	files, err := graph(htmlfile, app.Config.Static)
+	if err != nil {
+		l.Error().Err(err).Msg("No se pudo guardar la gráfica estática")
+	}
+	if len(files) > 0 {
+		l.Info().Strs("archivos", files).Msg("Gráficas estáticas guardadas")
+	}
+}
+
+// Imprime la tabla en la salida estándar: con barras y colores en una terminal, texto plano si no
+func (app *Application) Print(table *terminal.Table) {
+	l := app.Logger.With().Str("struct", "app").Str("method", "Print").Logger()
+
+	renderer, err := terminal.NewRenderer(os.Stdout, app.Config.Terminal)
+	if err != nil {
+		l.Error().Err(err).Msg("Modo de terminal no soportado! Use auto, rich o plain")
+		app.Config.Terminal.Mode = terminal.ModePlain
+		renderer, _ = terminal.NewRenderer(os.Stdout, app.Config.Terminal)
+	}
+	if err = renderer.Render(table); err != nil {
+		l.Error().Err(err).Msg("No se pudo imprimir la tabla")
+	}
+}
+
+// Guarda la sección del ejercicio y regenera el reporte html con los resultados de ambos ejercicios
+func (app *Application) UpdateDashboard(section dashboard.Section, chart staticchart.Chart) {
+	l := app.Logger.With().Str("struct", "app").Str("method", "UpdateDashboard").Logger()
+
+	if !app.Config.Dashboard.Enabled {
+		l.Trace().Msg("Reporte combinado deshabilitado")
+		return
+	}
+	if err := section.SetChart(chart, app.Config.Static); err != nil {
+		l.Error().Err(err).Msg("No se pudo dibujar la gráfica del reporte")
+	}
+	if err := dashboard.Update(section, app.Config.Dashboard, app.Logger); err != nil {
+		l.Error().Err(err).Msg("No se pudo actualizar el reporte combinado")
+		return
+	}
+	l.Info().Str("archivo", app.Config.Dashboard.HtmlFile).Msg("Reporte combinado actualizado")
+}
+
+// Servidor que comparte el reporte, las gráficas y los resultados, y puede lanzar ambos ejercicios
+func (app *Application) Server() (*server.Server, error) {
+	if err := app.Config.Dashboard.Validate(); err != nil {
+		app.Logger.Error().Str("struct", "app").Str("method", "Server").Err(err).Msg("El servidor compartiría la configuración y el historial!")
+		return nil, err
+	}
+	var charts []string
+	htmlfiles := []string{app.Config.HtmlFile, app.Config.ScatterHtml, app.Config.TableHtml, app.Config.CompareHtml,
+		app.Config.HistoryHtml, app.Config.Composite.HtmlFile, app.Config.Chart.WordCloud.HtmlFile}
+	for _, htmlfile := range htmlfiles {
+		charts = append(charts, htmlfile)
+		base := strings.TrimSuffix(htmlfile, filepath.Ext(htmlfile))
+		for _, format := range app.Config.Static.Formats {
+			charts = append(charts, base+"."+strings.ToLower(format))
+		}
+	}
+	charts = append(charts, app.Config.TableFile)
+
+	jobs := make(map[string]server.Job)
+	for kind := range app.exercises() {
+		kind := kind
+		jobs[kind] = func() error { return app.Run(kind) }
+	}
+	return server.NewServer(app.Config.Server, app.Config.Dashboard, app.History(), charts, jobs, app.Metrics, app.Logger), nil
+}
+
+// Daemon que corre ambos ejercicios según los horarios de la configuración
+func (app *Application) Daemon() (*daemon.Daemon, error) {
+	return app.daemon(app.Logger)
+}
+
+func (app *Application) daemon(logger zerolog.Logger) (*daemon.Daemon, error) {
+	jobs := make(map[string]daemon.Job)
+	for kind := range app.exercises() {
+		kind := kind
+		jobs[kind] = func() error { return app.Run(kind) }
+	}
+	return daemon.NewDaemon(app.Config.Daemon, jobs, logger)
+}
+
+func (app *Application) exercises() map[string]func() error {
+	return map[string]func() error{
+		history.KindLanguages: app.RunLanguages,
+		history.KindTags:      app.RunTags,
+	}
+}
+
+// Corre el ejercicio (languages o tags), registra el resultado en las métricas y avisa si falla
+func (app *Application) Run(kind string) error {
+	l := app.Logger.With().Str("struct", "app").Str("method", "Run").Str("kind", kind).Logger()
+
+	exercise, ok := app.exercises()[kind]
+	if !ok {
+		l.Error().Msg("Ejercicio no soportado! Use languages o tags")
+		return common.NewConfigError("kind", kind)
+	}
+	l.Trace().Msg("Tomando lock de corridas")
+	unlock, err := app.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	err = exercise()
+	result := "success"
+	if err != nil {
+		result = "failure"
+		app.NotifyFailure(kind, err)
+	} else {
+		app.Metrics.Set(metrics.LastSuccess, metrics.Labels{"kind": kind}, float64(time.Now().Unix()))
+	}
+	app.Metrics.Add(metrics.RunsTotal, metrics.Labels{"kind": kind, "result": result}, 1)
+	return err
+}
+
+// Toma el lock de daemon.lock_file, así una corrida de un comando, del servidor o del daemon nunca
+// se superpone con otra, aunque sea de otro proceso. Retorna la función que lo libera.
+func (app *Application) lock() (func(), error) {
+	l := app.Logger.With().Str("struct", "app").Str("method", "lock").Logger()
+
+	filename := app.Config.Daemon.LockFile
+	if filename == "" {
+		l.Trace().Msg("Sin lock de corridas")
+		return func() {}, nil
+	}
+	lock := daemon.NewLock(filename)
+	locked, err := lock.TryLock()
+	if err != nil {
+		l.Error().Err(err).Str("lock", filename).Msg("No se pudo tomar el lock")
+		return nil, err
+	}
+	if !locked {
+		l.Warn().Str("lock", filename).Msg("Otra corrida tiene el lock")
+		return nil, common.NewLockedError(filename)
+	}
+	return func() {
+		if err := lock.Unlock(); err != nil {
+			l.Error().Err(err).Str("lock", filename).Msg("No se pudo liberar el lock")
+		}
+	}, nil
+}
+
+// Última corrida guardada del tipo, nil si no hay o el historial está deshabilitado
+func (app *Application) lastRun(kind string) *history.Run {
+	l := app.Logger.With().Str("struct", "app").Str("method", "lastRun").Logger()
+
+	if !app.Config.History.Enabled {
+		return nil
+	}
+	runs, err := app.History().Latest(kind, 1)
+	if err != nil {
+		l.Warn().Err(err).Msg("No se pudo leer la corrida anterior")
+		return nil
+	}
+	if len(runs) == 0 {
+		return nil
+	}
+	return &runs[0]
+}
+
+// Avisa los cambios de puesto respecto a la corrida anterior y si fallaron demasiadas consultas
+func (app *Application) Notify(kind string, previous, current *history.Run, report scraping.ScrapeReport) {
+	l := app.Logger.With().Str("struct", "app").Str("method", "Notify").Logger()
+
+	if !app.Config.Notifications.Enabled {
+		l.Trace().Msg("Notificaciones deshabilitadas")
+		return
+	}
+	notifier := notify.NewNotifier(app.Config.Notifications, app.Logger)
+	var events []notify.Event
+	if previous != nil {
+		missing := append(append([]string{}, previous.Missing...), current.Missing...)
+		events = notifier.Compare(kind, previous.Values(), current.Values(), missing)
+	} else {
+		l.Info().Msg("Sin corrida anterior en el historial, no se comparan puestos")
+	}
+	events = append(events, notifier.CheckSuccess(kind, len(report.Records), report.Failures)...)
+	payload := notify.Payload{Kind: kind, RunID: current.ID, Timestamp: current.Timestamp, Events: events}
+	if payload.Timestamp.IsZero() {
+		payload.Timestamp = time.Now()
+	}
+	if err := notifier.Send(payload); err != nil {
+		l.Error().Err(err).Msg("No se pudieron enviar todas las notificaciones")
+	}
+}
+
+// Avisa que la corrida del ejercicio terminó con error
+func (app *Application) NotifyFailure(kind string, err error) {
+	l := app.Logger.With().Str("struct", "app").Str("method", "NotifyFailure").Logger()
+
+	if !app.Config.Notifications.Enabled {
+		return
+	}
+	notifier := notify.NewNotifier(app.Config.Notifications, app.Logger)
+	payload := notify.Payload{Kind: kind, Timestamp: time.Now(), Events: []notify.Event{notifier.Failure(kind, err)}}
+	if err := notifier.Send(payload); err != nil {
+		l.Error().Err(err).Msg("No se pudieron enviar todas las notificaciones")
+	}
+}
@@ -0,0 +1,211 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"webscraping/anomaly"
+	"webscraping/dashboard"
+	"webscraping/history"
+	"webscraping/metrics"
+	"webscraping/resultproc"
+	"webscraping/scraping"
+	"webscraping/staticchart"
+)
+
+// Ejercicio 1: scrapea la cantidad de repositorios de cada lenguaje en github, los guarda, exporta y grafica
+func (app *Application) RunLanguages() error {
+	l := app.Logger.With().Str("struct", "app").Str("method", "RunLanguages").Logger()
+
+	l.Trace().Msg("Creando objeto scraper")
+	sc := scraping.Scraper{Config: &app.Config.Scraper, Logger: app.Logger.With().Str("struct", "scraper").Logger(), Metrics: app.Metrics}
+	var listatiobe []string
+	var tiobe []scraping.TiobeLanguage
+	l.Trace().Msg("Verificando configuracion para determinar si usar lista estatica")
+	if !app.Config.UseFixedList {
+		l.Trace().Msg("Scrapeando tiobe")
+		var err error
+		tiobe, err = sc.ScrapeTiobe()
+		if err != nil {
+			l.Error().Err(err).Msg("Error scraping de tiobe!")
+			return err
+		}
+		for _, lang := range tiobe {
+			listatiobe = append(listatiobe, lang.Language)
+		}
+	} else {
+		l.Trace().Msg("Usando lista estatica")
+		listatiobe = app.Config.LangList
+	}
+	l.Trace().Msg("Intentando scraping de github")
+	langData, err := sc.ScrapeGithub(listatiobe)
+	if err != nil {
+		if len(langData) > 0 {
+			l.Error().Err(err).Msgf("Solo se procesaron %d/20 lenguajes! Por favor verificar conexión y aliases", len(langData))
+		} else {
+			l.Error().Err(err).Msg("No se pudieron procesar lenguajes! Cancelando...")
+			return err
+		}
+	}
+
+	l.Trace().Msg("Buscar valores anómalos")
+	current := make(map[string]float64)
+	for lang, num := range langData {
+		current[lang] = float64(num)
+	}
+	anomalies, err := app.CheckAnomalies(history.KindLanguages, current)
+	if len(anomalies) > 0 {
+		fmt.Print(anomaly.Report(app.Config.Anomalies.Method, anomalies))
+	}
+	if err != nil {
+		l.Error().Err(err).Msg("Cancelando por valores anómalos!")
+		return err
+	}
+	if app.Config.Anomalies.Action == anomaly.ActionExclude {
+		for _, a := range anomalies {
+			l.Info().Str("lang", a.Name).Msg("Excluyendo valor anómalo")
+			delete(langData, a.Name)
+			delete(current, a.Name)
+		}
+	}
+	app.Metrics.Replace(metrics.LanguageTopics, "language", current)
+	var missing []string
+	for _, lang := range listatiobe {
+		if _, ok := langData[lang]; !ok {
+			missing = append(missing, lang)
+		}
+	}
+
+	l.Trace().Msg("Obtener algoritmo de puntaje")
+	scorer, err := resultproc.GetScorer(app.Config.Scorer)
+	if err != nil {
+		l.Error().Err(err).Msg("Algoritmo de puntaje no soportado! Use minmax, zscore, log, percentile o share")
+		return err
+	}
+
+	l.Trace().Msg("Crear lista resultados")
+	res := resultproc.CreateLanguageResultList(langData, scorer, app.Logger)
+	l.Trace().Msg("Ordenar resultados")
+	res.ScoreSort()
+	res.NumSort()
+
+	l.Trace().Msg("Imprimir resultados")
+	app.Print(res.TerminalTable())
+	err = res.Graph(app.Config.HtmlFile, app.Config.Chart.Languages)
+	if err != nil {
+		l.Error().Err(err).Msg("No se pudo graficar")
+		return err
+	}
+	app.StaticGraph(app.Config.HtmlFile, func(base string, static staticchart.Config) ([]string, error) {
+		return res.StaticGraph(base, app.Config.Chart.Languages, static)
+	})
+
+	l.Trace().Msg("Guardar corrida en el historial")
+	run := history.Run{
+		Kind:       history.KindLanguages,
+		Scorer:     res.Scorer,
+		Languages:  langData,
+		Scores:     make(map[string]float64),
+		TiobeRanks: make(map[string]int),
+		Missing:    missing,
+	}
+	for _, lres := range res.Results() {
+		run.Scores[lres.Language] = float64(lres.Score)
+	}
+	for _, lang := range tiobe {
+		run.TiobeRanks[lang.Language] = lang.Rank
+	}
+	previous := app.lastRun(history.KindLanguages)
+	err = app.SaveRun(&run)
+	if err != nil {
+		l.Error().Err(err).Msg("No se pudo guardar la corrida en el historial")
+	}
+
+	l.Trace().Msg("Exportar resultados")
+	table := res.Table(app.Metadata(&run))
+	files, err := app.Export(table)
+	if err != nil {
+		l.Error().Err(err).Msg("No se pudieron exportar todos los formatos")
+	}
+	l.Info().Strs("archivos", files).Msg("Resultados exportados")
+
+	section := dashboard.Section{
+		Kind:      history.KindLanguages,
+		Title:     "Lenguajes en Github",
+		Table:     table,
+		ChartFile: app.Config.HtmlFile,
+		Exports:   files,
+	}
+	if len(tiobe) > 0 {
+		l.Trace().Msg("Calcular correlación de puestos tiobe vs github")
+		ratings := make(map[string]float64)
+		for _, lang := range tiobe {
+			ratings[lang.Language] = lang.Rating
+		}
+		corr := resultproc.CreateRankCorrelation(run.TiobeRanks, ratings, langData, app.Logger)
+		fmt.Print(corr.String())
+		section.Notes = append(section.Notes, corr.String())
+		err = corr.Graph(app.Config.ScatterHtml)
+		if err != nil {
+			l.Error().Err(err).Msg("No se pudo graficar la correlación")
+		}
+	}
+
+	if app.Config.Composite.Enabled {
+		l.Trace().Msg("Calcular índice compuesto")
+		err = app.composite(&sc, tiobe, langData)
+		if err != nil {
+			l.Error().Err(err).Msg("No se pudo calcular el índice compuesto")
+		}
+	}
+
+	l.Trace().Msg("Actualizar reporte combinado")
+	section.Scrape = sc.Report()
+	chart, err := res.StaticChart(app.Config.Chart.Languages)
+	if err == nil {
+		app.UpdateDashboard(section, chart)
+	}
+
+	l.Trace().Msg("Enviar notificaciones")
+	app.Notify(history.KindLanguages, previous, &run, section.Scrape)
+	return nil
+}
+
+func (app *Application) composite(sc *scraping.Scraper, tiobe []scraping.TiobeLanguage, langData map[string]int32) error {
+	l := app.Logger.With().Str("struct", "app").Str("method", "composite").Logger()
+
+	ratings := make(map[string]float64)
+	for _, lang := range tiobe {
+		ratings[lang.Language] = lang.Rating
+	}
+	if len(ratings) == 0 && app.Config.Composite.TiobeWeight > 0 {
+		l.Warn().Msg("No hay ratings de tiobe (lista fija), el componente tiobe no aporta")
+	}
+
+	mentions := make(map[string]int)
+	if app.Config.Composite.InterestWeight > 0 {
+		l.Trace().Msg("Scrapeando tags de interés para contar menciones")
+		interest, err := sc.ScrapeInterest()
+		if err != nil && len(interest.Topics) == 0 {
+			l.Error().Err(err).Msg("No se pudo scrapear tags de interés!")
+			return err
+		}
+		mentions = interest.Topics
+	}
+
+	var inputs []resultproc.CompositeInput
+	for lang, num := range langData {
+		inputs = append(inputs, resultproc.CompositeInput{
+			Language:         lang,
+			TiobeRating:      ratings[lang],
+			TopicNum:         num,
+			InterestMentions: mentions[strings.ToLower(lang)],
+		})
+	}
+	res := resultproc.CreateCompositeResultList(inputs, app.Config.Composite, app.Logger)
+	app.Print(res.TerminalTable())
+	err := res.Save(app.Config.Composite.ResultFile)
+	if err != nil {
+		return err
+	}
+	return res.Graph(app.Config.Composite.HtmlFile, app.Config.Chart.Composite)
+}
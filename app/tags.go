@@ -0,0 +1,163 @@
+package app
+
+import (
+	"fmt"
+	"webscraping/anomaly"
+	"webscraping/common"
+	"webscraping/dashboard"
+	"webscraping/history"
+	"webscraping/metrics"
+	"webscraping/resultproc"
+	"webscraping/scraping"
+	"webscraping/staticchart"
+)
+
+// Ejercicio 2: scrapea los tags de los repositorios de interés en github, los guarda, exporta y grafica
+func (app *Application) RunTags() error {
+	l := app.Logger.With().Str("struct", "app").Str("method", "RunTags").Logger()
+
+	l.Trace().Msg("Creando objeto scraper")
+	sc := scraping.Scraper{Config: &app.Config.Scraper, Logger: app.Logger.With().Str("struct", "scraper").Logger(), Metrics: app.Metrics}
+
+	l.Trace().Msg("Scrapeando github")
+	interest, err := sc.ScrapeInterest()
+	if err != nil {
+		if len(interest.Topics) > 0 {
+			l.Error().Err(err).Msgf("Solo se procesaron %d páginas! Por favor verificar conexión", interest.Report.PagesFetched)
+		} else {
+			l.Error().Err(err).Msg("No se pudo scrapear github!")
+			return err
+		}
+	}
+	l.Trace().Msg("Buscar valores anómalos")
+	current := make(map[string]float64)
+	for tag, num := range interest.Topics {
+		current[tag] = float64(num)
+	}
+	anomalies, err := app.CheckAnomalies(history.KindTags, current)
+	if len(anomalies) > 0 {
+		fmt.Print(anomaly.Report(app.Config.Anomalies.Method, anomalies))
+	}
+	if err != nil {
+		l.Error().Err(err).Msg("Cancelando por valores anómalos!")
+		return err
+	}
+	var missing []string
+	if app.Config.Anomalies.Action == anomaly.ActionExclude {
+		for _, a := range anomalies {
+			l.Info().Str("tag", a.Name).Msg("Excluyendo valor anómalo")
+			delete(interest.Topics, a.Name)
+			missing = append(missing, a.Name)
+		}
+	}
+
+	l.Trace().Msg("Creando lista resultado")
+	res := resultproc.CreateTagResultList(interest.Topics, app.Logger)
+	res.Interest = interest.Report.Interest
+	res.Sort = interest.Report.Sort
+	l.Trace().Msg("Ordenando resultados")
+	res.TagSort()
+	top := make(map[string]float64)
+	for i, tag := range res.Results() {
+		if i >= app.Config.Metrics.TopTags {
+			break
+		}
+		top[tag.Tag] = float64(tag.Num)
+	}
+	app.Metrics.Replace(metrics.TagCount, "tag", top)
+
+	l.Trace().Msg("Imprimir resultados")
+	app.Print(res.TerminalTable())
+	fmt.Print(interest.Report.String())
+
+	l.Trace().Msg("Guardar corrida en el historial")
+	run := history.Run{
+		Kind:     history.KindTags,
+		Interest: interest.Report.Interest,
+		Sort:     interest.Report.Sort,
+		Tags:     interest.Topics,
+		Missing:  missing,
+	}
+	previous := app.lastRun(history.KindTags)
+	err = app.SaveRun(&run)
+	if err != nil {
+		l.Error().Err(err).Msg("No se pudo guardar la corrida en el historial")
+	}
+
+	l.Trace().Msg("Exportar resultados")
+	results := res.Table(app.Metadata(&run))
+	files, err := app.Export(results)
+	if err != nil {
+		l.Error().Err(err).Msg("No se pudieron exportar todos los formatos")
+	}
+	l.Info().Strs("archivos", files).Msg("Resultados exportados")
+
+	l.Trace().Msg("Creando gráfica")
+	err = app.tagGraph(&res)
+	if err != nil {
+		l.Error().Err(err).Msg("No se pudo graficar")
+		return err
+	}
+	app.StaticGraph(app.Config.HtmlFile, func(base string, static staticchart.Config) ([]string, error) {
+		return res.StaticGraph(base, app.Config.Chart.Tags, static)
+	})
+
+	l.Trace().Msg("Creando tabla tags por lenguaje")
+	table := resultproc.CreateTagLanguageTable(interest.Languages, app.Logger)
+	table.Interest = interest.Report.Interest
+	table.Sort = interest.Report.Sort
+	fmt.Print(table.String())
+	err = table.Save(app.Config.TableFile)
+	if err != nil {
+		l.Error().Err(err).Msg("No se pudo guardar tabla de lenguajes")
+	} else {
+		files = append(files, app.Config.TableFile)
+	}
+	err = table.Graph(app.Config.TableHtml)
+	if err != nil {
+		l.Error().Err(err).Msg("No se pudo graficar tabla de lenguajes")
+	}
+	l.Trace().Msg("Actualizar reporte combinado")
+	chart, err := res.StaticChart(app.Config.Chart.Tags)
+	if err == nil {
+		app.UpdateDashboard(dashboard.Section{
+			Kind:      history.KindTags,
+			Title:     "Tags de interés",
+			Table:     results,
+			ChartFile: app.Config.HtmlFile,
+			Scrape:    sc.Report(),
+			Notes:     []string{interest.Report.String(), table.String()},
+			Exports:   files,
+		}, chart)
+	}
+
+	l.Trace().Msg("Enviar notificaciones")
+	app.Notify(history.KindTags, previous, &run, sc.Report())
+
+	l.Trace().Msg("Saliendo sin errores...")
+	return nil
+}
+
+// Gráfica de barras, nube de tags o ambas según chart.wordcloud.mode
+func (app *Application) tagGraph(res *resultproc.TagResultList) error {
+	l := app.Logger.With().Str("struct", "app").Str("method", "tagGraph").Logger()
+
+	wordcloud := app.Config.Chart.WordCloud
+	switch wordcloud.Mode {
+	case resultproc.WordCloudOff:
+		return res.Graph(app.Config.HtmlFile, app.Config.Chart.Tags)
+	case resultproc.WordCloudOnly:
+		l.Trace().Msg("Creando nube de tags en lugar de la gráfica")
+		return res.WordCloud(app.Config.HtmlFile, wordcloud)
+	case resultproc.WordCloudAlongside:
+		err := res.Graph(app.Config.HtmlFile, app.Config.Chart.Tags)
+		if err != nil {
+			return err
+		}
+		l.Trace().Str("html-file", wordcloud.HtmlFile).Msg("Creando nube de tags")
+		return res.WordCloud(wordcloud.HtmlFile, wordcloud)
+	default:
+		l.Error().Str("mode", wordcloud.Mode).Msg("Modo de nube de tags no soportado! Use off, alongside u only")
+		return common.NewConfigError("wordcloud.mode", wordcloud.Mode)
+	}
+}
@@ -0,0 +1,57 @@
+package app
+
+import (
+	"webscraping/anomaly"
+	"webscraping/export"
+	"webscraping/forecast"
+	"webscraping/resultproc"
+
+	"github.com/rs/zerolog"
+)
+
+// Revisa toda la configuración sin hacer consultas. Retorna un error por cada valor inválido.
+func (app *Application) Validate() []error {
+	l := app.Logger.With().Str("struct", "app").Str("method", "Validate").Logger()
+
+	var errs []error
+	check := func(err error) {
+		if err != nil {
+			l.Trace().Err(err).Msg("Valor inválido")
+			errs = append(errs, err)
+		}
+	}
+	nop := app.Logger.Level(zerolog.Disabled)
+
+	l.Trace().Msg("Revisando scraper")
+	check(app.Config.Scraper.Validate(nop))
+	_, err := resultproc.GetScorer(app.Config.Scorer)
+	check(err)
+	for _, format := range app.Config.Formats {
+		_, err = export.GetExporter(format)
+		check(err)
+	}
+
+	l.Trace().Msg("Revisando gráficas")
+	check(app.Config.Chart.Languages.Validate())
+	check(app.Config.Chart.Tags.Validate())
+	check(app.Config.Chart.Composite.Validate())
+	check(app.Config.Chart.WordCloud.Validate())
+	check(app.Config.Static.Validate())
+	check(app.Config.Terminal.Validate())
+	check(app.Config.Dashboard.Validate())
+
+	l.Trace().Msg("Revisando pronóstico y anomalías")
+	_, err = forecast.GetForecaster(app.Config.Forecast)
+	check(err)
+	_, err = anomaly.NewDetector(app.Config.Anomalies, nop)
+	check(err)
+
+	l.Trace().Msg("Revisando daemon y notificaciones")
+	// Sin horarios no se usa el daemon y NewDaemon fallaría por no tener ejercicios
+	if len(app.Config.Daemon.Schedules) > 0 {
+		_, err = app.daemon(nop)
+		check(err)
+	}
+	check(app.Config.Notifications.Validate())
+	return errs
+}
@@ -0,0 +1,44 @@
+package app
+
+import (
+	"testing"
+)
+
+func testApplication(t *testing.T) *Application {
+	t.Helper()
+	var app Application
+	if err := app.Configure("disabled"); err != nil {
+		t.Fatal(err)
+	}
+	return &app
+}
+
+func TestValidateDefaults(t *testing.T) {
+	app := testApplication(t)
+	if errs := app.Validate(); len(errs) != 0 {
+		t.Errorf("errs = %v", errs)
+	}
+}
+
+func TestValidateDaemon(t *testing.T) {
+	tests := []struct {
+		name      string
+		schedules map[string]string
+		errs      int
+	}{
+		{"sin horarios", nil, 0},
+		{"horarios vacíos", map[string]string{}, 0},
+		{"horario válido", map[string]string{"tags": "0 6 * * *"}, 0},
+		{"horario inválido", map[string]string{"tags": "0 25 * * *"}, 1},
+		{"ejercicio desconocido", map[string]string{"otro": "0 6 * * *"}, 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			app := testApplication(t)
+			app.Config.Daemon.Schedules = test.schedules
+			if errs := app.Validate(); len(errs) != test.errs {
+				t.Errorf("errs = %v, want %d", errs, test.errs)
+			}
+		})
+	}
+}
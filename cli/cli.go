@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+	"webscraping/app"
+
+	flag "github.com/spf13/pflag"
+)
+
+const program = "webscraping"
+
+// Un subcomando del binario
+type command struct {
+	name string
+	// Otros nombres aceptados para el comando
+	aliases []string
+	args    string
+	short   string
+	// Solo muestra errores por defecto, para comandos cuya salida se redirige
+	quiet bool
+	// Registra los flags propios y retorna la función que corre el comando con la aplicación ya configurada
+	setup func(flags *flag.FlagSet) func(app *app.Application, args []string) error
+}
+
+// Corre el subcomando de args[0] con el resto de los argumentos. Retorna el código de salida.
+func Main(args []string) int {
+	if len(args) == 0 {
+		usage(os.Stderr)
+		return 2
+	}
+	switch args[0] {
+	case "help", "-h", "--help":
+		if len(args) > 1 {
+			return Main([]string{args[1], "--help"})
+		}
+		usage(os.Stdout)
+		return 0
+	}
+	cmd := find(args[0])
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "Comando desconocido: %v\n\n", args[0])
+		usage(os.Stderr)
+		return 2
+	}
+
+	start := time.Now()
+	var app app.Application
+
+	global := flag.NewFlagSet("global", flag.ContinueOnError)
+	defaultlevel := "info"
+	if cmd.quiet {
+		defaultlevel = "error"
+	}
+	loglevel := global.StringP("loglevel", "l", defaultlevel, "Nivel de logs: trace, debug, info, warn, error o disabled")
+	global.StringSliceVarP(&app.ConfigFiles, "configfile", "c", []string{"resource/config/app.config"}, "Archivos de configuración, cada uno pisa los valores del anterior (repetible)")
+	global.StringArrayVar(&app.Overrides, "set", nil, "Pisa un valor de la configuración, por ejemplo --set scraper.max_parallel=4 (repetible)")
+
+	flags := flag.NewFlagSet(program+" "+cmd.name, flag.ContinueOnError)
+	run := cmd.setup(flags)
+	own := flags.FlagUsages()
+	flags.AddFlagSet(global)
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Uso: %v\n\n%v\n\n", strings.TrimSpace(program+" "+cmd.name+" [flags] "+cmd.args), cmd.short)
+		if own != "" {
+			fmt.Fprintf(os.Stderr, "Flags:\n%v\n", own)
+		}
+		fmt.Fprintf(os.Stderr, "Flags globales:\n%v", global.FlagUsages())
+	}
+	err := flags.Parse(args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\nUsar \"%v %v --help\" para ver los flags.\n", err, program, cmd.name)
+		return 2
+	}
+
+	err = app.Configure(*loglevel)
+	if err != nil {
+		app.Logger.Err(err).Msg("Error configurando aplicacion. Terminando...")
+		return 1
+	}
+	l := app.Logger.With().Str("function", "main").Str("command", cmd.name).Logger()
+	l.Info().Msg("Aplicacion lanzada!")
+	l.Trace().Msg("Aplicacion configurada sin errores.")
+
+	l.Trace().Msg("Corriendo app")
+	err = run(&app, flags.Args())
+	if err != nil {
+		l.Err(err).Msg("Error corriendo app. Apagando...")
+		return 1
+	}
+
+	l.Info().Msgf("Completando en %v", time.Since(start))
+	return 0
+}
+
+func find(name string) *command {
+	for i := range commands {
+		if commands[i].name == name {
+			return &commands[i]
+		}
+		for _, alias := range commands[i].aliases {
+			if alias == name {
+				return &commands[i]
+			}
+		}
+	}
+	return nil
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintf(w, "Uso: %v <comando> [flags]\n\nComandos:\n", program)
+	width := 0
+	for _, cmd := range commands {
+		if len(cmd.name) > width {
+			width = len(cmd.name)
+		}
+	}
+	for _, cmd := range commands {
+		short := cmd.short
+		if len(cmd.aliases) > 0 {
+			short += fmt.Sprintf(" (alias: %v)", strings.Join(cmd.aliases, ", "))
+		}
+		fmt.Fprintf(w, "  %v%v  %v\n", cmd.name, strings.Repeat(" ", width-len(cmd.name)), short)
+	}
+	fmt.Fprintf(w, "\nUsar \"%v <comando> --help\" para ver los flags de cada comando.\n", program)
+}
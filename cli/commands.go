@@ -0,0 +1,208 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"webscraping/app"
+	"webscraping/common"
+	"webscraping/history"
+
+	flag "github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+var commands = []command{
+	{
+		name:  "languages",
+		short: "Ejercicio 1: cantidad de repositorios en github de los lenguajes de tiobe",
+		setup: languages,
+	},
+	{
+		name:  "tags",
+		short: "Ejercicio 2: tags más mencionados en los repositorios de interés",
+		setup: tags,
+	},
+	{
+		name:  "compare",
+		short: "Compara dos corridas del historial",
+		setup: compare,
+	},
+	{
+		name:    "history-chart",
+		aliases: []string{"history"},
+		short:   "Grafica y pronostica la evolución de las corridas del historial",
+		setup:   historyChart,
+	},
+	{
+		name:  "serve",
+		short: "Servidor HTTP con el reporte, los resultados y corridas en segundo plano",
+		setup: serve,
+	},
+	{
+		name:  "daemon",
+		short: "Corre los ejercicios periódicamente según daemon.schedules",
+		setup: daemon,
+	},
+	{
+		name:  "validate",
+		short: "Revisa la configuración sin hacer consultas",
+		setup: validate,
+	},
+	{
+		name:  "config",
+		args:  "[show]",
+		short: "Imprime la configuración con todas las capas aplicadas",
+		quiet: true,
+		setup: config,
+	},
+}
+
+// Flag de formatos de exportación compartido por ambos ejercicios
+func formatFlag(flags *flag.FlagSet) *[]string {
+	return flags.StringSliceP("format", "f", nil, "Formatos de exportación: csv, json, markdown, yaml o xlsx (pisa la configuración)")
+}
+
+// Corre el ejercicio y abre la gráfica
+func exercise(app *app.Application, kind string) error {
+	l := app.Logger.With().Str("function", "exercise").Logger()
+
+	err := app.Run(kind)
+	if err != nil {
+		return err
+	}
+	l.Trace().Msg("Abriendo archivo grafica")
+	err = app.OpenGraph()
+	if err != nil {
+		fmt.Printf("Para visualizar el resultado abre %v en su navegador.", app.Config.HtmlFile)
+	}
+	return nil
+}
+
+func languages(flags *flag.FlagSet) func(app *app.Application, args []string) error {
+	formats := formatFlag(flags)
+	return func(app *app.Application, args []string) error {
+		if len(*formats) > 0 {
+			app.Config.Formats = *formats
+		}
+		return exercise(app, history.KindLanguages)
+	}
+}
+
+func tags(flags *flag.FlagSet) func(app *app.Application, args []string) error {
+	formats := formatFlag(flags)
+	wordcloud := flags.StringP("wordcloud", "w", "", "Nube de tags: off, alongside u only (pisa la configuración)")
+	return func(app *app.Application, args []string) error {
+		if len(*formats) > 0 {
+			app.Config.Formats = *formats
+		}
+		if *wordcloud != "" {
+			app.Config.Chart.WordCloud.Mode = *wordcloud
+		}
+		return exercise(app, history.KindTags)
+	}
+}
+
+// Contexto que se cancela con SIGINT o SIGTERM
+func signalContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+}
+
+func serve(flags *flag.FlagSet) func(app *app.Application, args []string) error {
+	address := flags.StringP("address", "a", "", "Dirección donde escuchar, por ejemplo :8080 (por defecto la de la configuración)")
+	return func(app *app.Application, args []string) error {
+		if *address != "" {
+			app.Config.Server.Address = *address
+		}
+		srv, err := app.Server()
+		if err != nil {
+			return err
+		}
+		ctx, stop := signalContext()
+		defer stop()
+		return srv.Serve(ctx)
+	}
+}
+
+func daemon(flags *flag.FlagSet) func(app *app.Application, args []string) error {
+	return func(app *app.Application, args []string) error {
+		l := app.Logger.With().Str("function", "daemon").Logger()
+
+		if !app.Config.History.Enabled {
+			l.Warn().Msg("El daemon siempre guarda las corridas en el historial, habilitándolo")
+			app.Config.History.Enabled = true
+		}
+		l.Trace().Msg("Creando daemon")
+		d, err := app.Daemon()
+		if err != nil {
+			return err
+		}
+
+		ctx, stop := signalContext()
+		defer stop()
+		if app.Metrics != nil && app.Config.Metrics.Address != "" {
+			go func() {
+				if err := app.Metrics.Serve(ctx, app.Config.Metrics.Address, app.Logger); err != nil {
+					l.Err(err).Msg("No se pudieron publicar las métricas")
+				}
+			}()
+		}
+		return d.Run(ctx)
+	}
+}
+
+func validate(flags *flag.FlagSet) func(app *app.Application, args []string) error {
+	return func(app *app.Application, args []string) error {
+		errs := app.Validate()
+		for _, err := range errs {
+			fmt.Println(err)
+		}
+		if len(errs) > 0 {
+			return errs[0]
+		}
+		fmt.Printf("Configuración válida: %v\n", strings.Join(app.ConfigFiles, ", "))
+		return nil
+	}
+}
+
+func config(flags *flag.FlagSet) func(app *app.Application, args []string) error {
+	effective := flags.BoolP("effective", "e", false, "Imprime cada valor con la capa de la que viene: default, file, env o --set")
+	return func(app *app.Application, args []string) error {
+		if len(args) > 1 || (len(args) == 1 && args[0] != "show") {
+			return common.NewConfigError("config", fmt.Sprint(args))
+		}
+		if *effective {
+			return printEffective(app)
+		}
+		encoder := yaml.NewEncoder(os.Stdout)
+		encoder.SetIndent(4)
+		if err := encoder.Encode(&app.Config); err != nil {
+			return err
+		}
+		return encoder.Close()
+	}
+}
+
+// Una línea por valor: ruta y origen en columnas y el valor al final, que puede ser largo
+func printEffective(app *app.Application) error {
+	settings, err := app.Layers.Effective(&app.Config)
+	if err != nil {
+		return err
+	}
+	path, source := 0, 0
+	for _, setting := range settings {
+		if len(setting.Path) > path {
+			path = len(setting.Path)
+		}
+		if len(setting.Source) > source {
+			source = len(setting.Source)
+		}
+	}
+	for _, setting := range settings {
+		fmt.Printf("%-*v  %-*v  %v\n", path, setting.Path, source, setting.Source, setting.Value)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"fmt"
+	"webscraping/app"
+	"webscraping/history"
+	"webscraping/resultproc"
+
+	flag "github.com/spf13/pflag"
+)
+
+func compare(flags *flag.FlagSet) func(app *app.Application, args []string) error {
+	kind := flags.StringP("kind", "k", history.KindLanguages, "Tipo de corrida a comparar: languages o tags")
+	oldref := flags.StringP("old", "a", "previous", "Corrida anterior: latest, previous, ID o fecha (2006-01-02)")
+	newref := flags.StringP("new", "b", "latest", "Corrida actual: latest, previous, ID o fecha (2006-01-02)")
+	return func(app *app.Application, args []string) error {
+		return runCompare(app, *kind, *oldref, *newref)
+	}
+}
+
+func runCompare(app *app.Application, kind, oldref, newref string) error {
+	l := app.Logger.With().Str("function", "compare").Logger()
+
+	l.Trace().Msg("Abriendo historial")
+	store := app.History()
+	oldrun, err := store.Resolve(kind, oldref)
+	if err != nil {
+		l.Error().Err(err).Str("ref", oldref).Msg("No se encontró la corrida anterior")
+		return err
+	}
+	newrun, err := store.Resolve(kind, newref)
+	if err != nil {
+		l.Error().Err(err).Str("ref", newref).Msg("No se encontró la corrida actual")
+		return err
+	}
+
+	l.Trace().Msg("Comparando corridas")
+	missing := append(append([]string{}, oldrun.Missing...), newrun.Missing...)
+	res := resultproc.CreateDeltaResultList(oldrun.Values(), newrun.Values(), missing, app.Logger)
+	res.Title = fmt.Sprintf("Cambios %v: %v -> %v", kind, oldrun.ID, newrun.ID)
+
+	l.Trace().Msg("Imprimir resultados")
+	app.Print(res.TerminalTable())
+
+	l.Trace().Msg("Creando gráfica")
+	err = res.Graph(app.Config.CompareHtml)
+	if err != nil {
+		l.Error().Err(err).Msg("No se pudo graficar")
+		return err
+	}
+	fmt.Printf("Gráfica de cambios guardada en %v\n", app.Config.CompareHtml)
+	return nil
+}
@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+	"webscraping/app"
+	"webscraping/common"
+	"webscraping/forecast"
+	"webscraping/history"
+	"webscraping/resultproc"
+
+	flag "github.com/spf13/pflag"
+)
+
+func historyChart(flags *flag.FlagSet) func(app *app.Application, args []string) error {
+	kind := flags.StringP("kind", "k", history.KindLanguages, "Tipo de corrida a graficar: languages o tags")
+	metric := flags.StringP("metric", "m", history.MetricCounts, "Valor a graficar: counts o scores (solo languages)")
+	from := flags.String("from", "", "Fecha inicial (2006-01-02)")
+	to := flags.String("to", "", "Fecha final inclusive (2006-01-02)")
+	top := flags.IntP("top", "n", 10, "Cantidad de series con mayor valor en la última corrida, 0 para todas")
+	normalized := flags.Bool("normalized", false, "Graficar índice con la primera corrida = 100 en vez de valores absolutos")
+	weeks := flags.IntP("forecast-weeks", "w", -1, "Semanas a pronosticar, 0 para no pronosticar (por defecto el de la configuración)")
+	model := flags.String("model", "", "Modelo de pronóstico: linear o holt (por defecto el de la configuración)")
+	return func(app *app.Application, args []string) error {
+		l := app.Logger.With().Str("function", "history").Logger()
+
+		if *weeks >= 0 {
+			app.Config.Forecast.Weeks = *weeks
+		}
+		if *model != "" {
+			app.Config.Forecast.Model = *model
+		}
+
+		l.Trace().Msg("Leyendo rango de fechas")
+		query := history.Query{Kind: *kind}
+		var err error
+		if *from != "" {
+			query.From, err = time.ParseInLocation("2006-01-02", *from, time.Local)
+			if err != nil {
+				l.Error().Err(err).Msg("Fecha inicial inválida")
+				return common.NewConfigError("from", *from)
+			}
+		}
+		if *to != "" {
+			query.To, err = time.ParseInLocation("2006-01-02", *to, time.Local)
+			if err != nil {
+				l.Error().Err(err).Msg("Fecha final inválida")
+				return common.NewConfigError("to", *to)
+			}
+			query.To = query.To.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		}
+		return runHistory(app, query, *metric, *top, *normalized)
+	}
+}
+
+func runHistory(app *app.Application, query history.Query, metric string, top int, normalized bool) error {
+	l := app.Logger.With().Str("function", "history").Logger()
+
+	l.Trace().Msg("Leyendo historial")
+	runs, err := app.History().Query(query)
+	if err != nil {
+		l.Error().Err(err).Msg("No se pudo leer el historial")
+		return err
+	}
+	l.Info().Int("corridas", len(runs)).Msg("Corridas encontradas")
+
+	var points []resultproc.HistoryPoint
+	for _, run := range runs {
+		points = append(points, resultproc.HistoryPoint{Time: run.Timestamp, Values: run.Metric(metric)})
+	}
+
+	l.Trace().Msg("Creando gráfica")
+	chart := resultproc.CreateHistoryChart(points, top, normalized, app.Logger)
+	chart.Title = fmt.Sprintf("Historial %v (%v)", query.Kind, metric)
+
+	if app.Config.Forecast.Weeks > 0 {
+		l.Trace().Msg("Pronosticando series")
+		forecaster, err := forecast.GetForecaster(app.Config.Forecast)
+		if err != nil {
+			l.Error().Err(err).Msg("Modelo de pronóstico no soportado! Use linear o holt")
+			return err
+		}
+		forecasts := resultproc.CreateForecastResultList(points, forecaster, app.Config.Forecast.Weeks, app.Logger)
+		fmt.Print(forecasts.String())
+		chart.SetForecast(&forecasts)
+	}
+
+	err = chart.Graph(app.Config.HistoryHtml)
+	if err != nil {
+		l.Error().Err(err).Msg("No se pudo graficar")
+		return err
+	}
+	fmt.Printf("Gráfica del historial guardada en %v\n", app.Config.HistoryHtml)
+	app.StaticGraph(app.Config.HistoryHtml, chart.StaticGraph)
+	return nil
+}
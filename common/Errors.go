@@ -8,6 +8,18 @@ type ParseError struct {
 type StatusCodeError struct {
 	code int
 }
+type ConfigError struct {
+	field, value string
+}
+type NotFoundError struct {
+	object string
+}
+type AnomalyError struct {
+	count int
+}
+type LockedError struct {
+	filename string
+}
 
 func (err *ParseError) Error() string {
 	return "No se pudo leer " + err.parseobject
@@ -17,6 +29,22 @@ func (err *StatusCodeError) Error() string {
 	return fmt.Sprintf("El último código error fue %d", err.code)
 }
 
+func (err *ConfigError) Error() string {
+	return fmt.Sprintf("Valor inválido para %v: '%v'", err.field, err.value)
+}
+
+func (err *NotFoundError) Error() string {
+	return "No se encontró " + err.object
+}
+
+func (err *AnomalyError) Error() string {
+	return fmt.Sprintf("Se detectaron %d valores anómalos", err.count)
+}
+
+func (err *LockedError) Error() string {
+	return "Otra corrida tiene el lock " + err.filename
+}
+
 func NewParseError(parseobject string) *ParseError {
 	err := ParseError{parseobject: parseobject}
 	return &err
@@ -26,3 +54,23 @@ func NewStatusCodeError(code int) *StatusCodeError {
 	err := StatusCodeError{code: code}
 	return &err
 }
+
+func NewConfigError(field, value string) *ConfigError {
+	err := ConfigError{field: field, value: value}
+	return &err
+}
+
+func NewNotFoundError(object string) *NotFoundError {
+	err := NotFoundError{object: object}
+	return &err
+}
+
+func NewAnomalyError(count int) *AnomalyError {
+	err := AnomalyError{count: count}
+	return &err
+}
+
+func NewLockedError(filename string) *LockedError {
+	err := LockedError{filename: filename}
+	return &err
+}
@@ -0,0 +1,136 @@
+package daemon
+
+import (
+	"strconv"
+	"strings"
+	"time"
+	"webscraping/common"
+)
+
+// Horario con el formato de cron: minuto hora día-del-mes mes día-de-la-semana
+type Schedule struct {
+	minutes, hours, days, months, weekdays uint64
+	// Como en cron, si se restringen el día del mes y el de la semana basta con que coincida uno
+	anyDay, anyWeekday bool
+}
+
+var macros = map[string]string{
+	"@hourly":  "0 * * * *",
+	"@daily":   "0 0 * * *",
+	"@weekly":  "0 0 * * 0",
+	"@monthly": "0 0 1 * *",
+}
+
+// Lee un horario de cron. Cada campo acepta *, valores, rangos (1-5), listas (1,3) y pasos (*/15, 0-30/10).
+func ParseSchedule(expr string) (*Schedule, error) {
+	spec := strings.TrimSpace(expr)
+	if macro, ok := macros[spec]; ok {
+		spec = macro
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, common.NewParseError("el horario '" + expr + "'")
+	}
+	var schedule Schedule
+	var err error
+	bounds := []struct {
+		field    *uint64
+		min, max int
+	}{
+		{&schedule.minutes, 0, 59},
+		{&schedule.hours, 0, 23},
+		{&schedule.days, 1, 31},
+		{&schedule.months, 1, 12},
+		{&schedule.weekdays, 0, 7},
+	}
+	for i, b := range bounds {
+		*b.field, err = parseField(fields[i], b.min, b.max)
+		if err != nil {
+			return nil, common.NewParseError("el horario '" + expr + "'")
+		}
+	}
+	// El 7 también es domingo
+	if schedule.weekdays&(1<<7) != 0 {
+		schedule.weekdays |= 1
+	}
+	// Como en cron, un campo que empieza con * (por ejemplo */2) no restringe para esta regla
+	schedule.anyDay = strings.HasPrefix(fields[2], "*")
+	schedule.anyWeekday = strings.HasPrefix(fields[4], "*")
+	return &schedule, nil
+}
+
+func parseField(field string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			var err error
+			step, err = strconv.Atoi(part[i+1:])
+			if err != nil || step <= 0 {
+				return 0, common.NewParseError(part)
+			}
+			part = part[:i]
+		}
+		start, end := min, max
+		if part != "*" {
+			bounds := strings.SplitN(part, "-", 2)
+			var err error
+			start, err = strconv.Atoi(bounds[0])
+			if err != nil {
+				return 0, common.NewParseError(part)
+			}
+			end = start
+			if len(bounds) == 2 {
+				end, err = strconv.Atoi(bounds[1])
+				if err != nil {
+					return 0, common.NewParseError(part)
+				}
+			} else if step > 1 {
+				// 5/15 es lo mismo que 5-max/15
+				end = max
+			}
+		}
+		if start < min || end > max || start > end {
+			return 0, common.NewParseError(part)
+		}
+		for v := start; v <= end; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func (schedule *Schedule) matchesDay(t time.Time) bool {
+	day := schedule.days&(1<<uint(t.Day())) != 0
+	weekday := schedule.weekdays&(1<<uint(t.Weekday())) != 0
+	if schedule.anyDay || schedule.anyWeekday {
+		return day && weekday
+	}
+	return day || weekday
+}
+
+// Primer momento del horario estrictamente posterior a t, o cero si no hay ninguno en los próximos 5 años
+func (schedule *Schedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if schedule.months&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !schedule.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if schedule.hours&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if schedule.minutes&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
@@ -0,0 +1,93 @@
+package daemon
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSchedule(t *testing.T) {
+	valid := []string{
+		"* * * * *",
+		"*/15 * * * *",
+		"0-30/10 * * * *",
+		"5/15 * * * *",
+		"0 6,18 * * 1-5",
+		"0 0 1 1 7",
+		" @daily ",
+		"@hourly",
+		"@weekly",
+		"@monthly",
+	}
+	for _, expr := range valid {
+		if _, err := ParseSchedule(expr); err != nil {
+			t.Errorf("%q: %v", expr, err)
+		}
+	}
+
+	invalid := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * 32 * *",
+		"* * * 0 *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"1-b * * * *",
+		"@yearly",
+	}
+	for _, expr := range invalid {
+		if _, err := ParseSchedule(expr); err == nil {
+			t.Errorf("%q: expected error", expr)
+		}
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	date := func(year int, month time.Month, day, hour, min, sec int) time.Time {
+		return time.Date(year, month, day, hour, min, sec, 0, time.UTC)
+	}
+	tests := []struct {
+		name string
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{"pasos", "*/15 * * * *", date(2024, 1, 1, 10, 7, 30), date(2024, 1, 1, 10, 15, 0)},
+		{"rango con paso", "0-30/10 * * * *", date(2024, 1, 1, 10, 31, 0), date(2024, 1, 1, 11, 0, 0)},
+		{"estrictamente posterior", "0 6 * * *", date(2024, 1, 1, 6, 0, 0), date(2024, 1, 2, 6, 0, 0)},
+		{"lista de horas", "0 6,18 * * *", date(2024, 1, 1, 6, 0, 0), date(2024, 1, 1, 18, 0, 0)},
+		{"hourly", "@hourly", date(2024, 1, 1, 10, 0, 0), date(2024, 1, 1, 11, 0, 0)},
+		// 2024-01-01 es lunes
+		{"domingo como 0", "0 0 * * 0", date(2024, 1, 1, 0, 0, 0), date(2024, 1, 7, 0, 0, 0)},
+		{"domingo como 7", "0 0 * * 7", date(2024, 1, 1, 0, 0, 0), date(2024, 1, 7, 0, 0, 0)},
+		{"días hábiles", "0 9 * * 1-5", date(2024, 1, 5, 10, 0, 0), date(2024, 1, 8, 9, 0, 0)},
+		// Con día del mes y de la semana restringidos basta con que coincida uno
+		{"viernes o 13", "0 0 13 * 5", date(2024, 1, 1, 0, 0, 0), date(2024, 1, 5, 0, 0, 0)},
+		{"viernes o 13, el 13", "0 0 13 * 5", date(2024, 1, 12, 0, 0, 0), date(2024, 1, 13, 0, 0, 0)},
+		// Un campo que empieza con * exige que coincidan ambos: lunes impares
+		{"paso en el día del mes", "0 0 */2 * 1", date(2024, 1, 1, 0, 0, 0), date(2024, 1, 15, 0, 0, 0)},
+		{"paso en el día de la semana", "0 0 8 * */2", date(2024, 1, 1, 0, 0, 0), date(2024, 2, 8, 0, 0, 0)},
+		{"mes sin el día", "0 0 31 * *", date(2024, 1, 31, 0, 0, 0), date(2024, 3, 31, 0, 0, 0)},
+		{"fin de año", "30 23 31 12 *", date(2024, 12, 31, 23, 30, 0), date(2025, 12, 31, 23, 30, 0)},
+		{"año bisiesto", "0 0 29 2 *", date(2024, 3, 1, 0, 0, 0), date(2028, 2, 29, 0, 0, 0)},
+		{"monthly", "@monthly", date(2024, 1, 15, 0, 0, 0), date(2024, 2, 1, 0, 0, 0)},
+		{"nunca", "0 0 30 2 *", date(2024, 1, 1, 0, 0, 0), time.Time{}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			schedule, err := ParseSchedule(test.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := schedule.Next(test.from); !got.Equal(test.want) {
+				t.Errorf("Next(%v) = %v, want %v", test.from, got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sort"
+	"time"
+	"webscraping/common"
+
+	"github.com/rs/zerolog"
+)
+
+type Config struct {
+	// Horario de cron de cada ejercicio (languages, tags). Vacío para no correrlo.
+	Schedules map[string]string `json:"schedules" yaml:"schedules"`
+	// Cada corrida empieza entre 0 y jitter_seconds después de su horario, para no consultar siempre al mismo segundo
+	JitterSeconds int `json:"jitter_seconds" yaml:"jitter_seconds"`
+	// Después de n fallas seguidas se espera al menos backoff_seconds * 2^(n-1), hasta max_backoff_seconds
+	BackoffSeconds    int `json:"backoff_seconds" yaml:"backoff_seconds"`
+	MaxBackoffSeconds int `json:"max_backoff_seconds" yaml:"max_backoff_seconds"`
+	// Lock que toma cada corrida, del daemon, del servidor o de un comando. Vacío para no usarlo.
+	LockFile string `json:"lock_file" yaml:"lock_file"`
+}
+
+// Un ejercicio que corre según su horario, como app.RunLanguages. Si otra corrida tiene el lock
+// retorna un common.LockedError y la corrida se salta sin contar como falla.
+type Job func() error
+
+type entry struct {
+	kind     string
+	schedule *Schedule
+	job      Job
+	failures int
+	next     time.Time
+}
+
+type Daemon struct {
+	config  Config
+	entries []*entry
+	random  *rand.Rand
+	logger  zerolog.Logger
+}
+
+func GetDefaultConfig() Config {
+	return Config{
+		Schedules: map[string]string{
+			"languages": "0 6 * * *",
+			"tags":      "30 6 * * *",
+		},
+		JitterSeconds:     300,
+		BackoffSeconds:    600,
+		MaxBackoffSeconds: 86400,
+		LockFile:          "webscraping.lock",
+	}
+}
+
+func NewDaemon(config Config, jobs map[string]Job, logger zerolog.Logger) (*Daemon, error) {
+	l := logger.With().Str("function", "NewDaemon").Logger()
+
+	var daemon Daemon
+	daemon.config = config
+	daemon.random = rand.New(rand.NewSource(time.Now().UnixNano()))
+	daemon.logger = logger.With().Str("struct", "Daemon").Logger()
+
+	kinds := make([]string, 0, len(config.Schedules))
+	for kind := range config.Schedules {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	for _, kind := range kinds {
+		expr := config.Schedules[kind]
+		if expr == "" {
+			l.Info().Str("kind", kind).Msg("Ejercicio sin horario, no se corre")
+			continue
+		}
+		job, ok := jobs[kind]
+		if !ok {
+			l.Error().Str("kind", kind).Msg("Ejercicio no soportado! Use languages o tags")
+			return nil, common.NewConfigError("daemon.schedules", kind)
+		}
+		schedule, err := ParseSchedule(expr)
+		if err != nil {
+			l.Error().Err(err).Str("kind", kind).Msg("Horario inválido")
+			return nil, common.NewConfigError("daemon.schedules."+kind, expr)
+		}
+		daemon.entries = append(daemon.entries, &entry{kind: kind, schedule: schedule, job: job})
+	}
+	if len(daemon.entries) == 0 {
+		return nil, common.NewNotFoundError("ningún ejercicio con horario en daemon.schedules")
+	}
+	return &daemon, nil
+}
+
+// Próximo horario del ejercicio, salteando los que caen dentro del backoff y sumando el jitter
+func (daemon *Daemon) schedule(e *entry, now time.Time) {
+	from := now
+	if e.failures > 0 {
+		backoff := time.Duration(daemon.config.BackoffSeconds) * time.Second
+		for i := 1; i < e.failures && backoff < time.Duration(daemon.config.MaxBackoffSeconds)*time.Second; i++ {
+			backoff *= 2
+		}
+		if max := time.Duration(daemon.config.MaxBackoffSeconds) * time.Second; backoff > max {
+			backoff = max
+		}
+		from = now.Add(backoff)
+	}
+	e.next = e.schedule.Next(from)
+	if !e.next.IsZero() && daemon.config.JitterSeconds > 0 {
+		e.next = e.next.Add(time.Duration(daemon.random.Int63n(int64(daemon.config.JitterSeconds) * int64(time.Second))))
+	}
+	daemon.logger.Info().Str("kind", e.kind).Int("fallas", e.failures).Time("proxima", e.next).Msg("Corrida programada")
+}
+
+// Corre los ejercicios según sus horarios hasta que se cancele el contexto.
+// Si se cancela durante una corrida se espera a que termine.
+func (daemon *Daemon) Run(ctx context.Context) error {
+	l := daemon.logger.With().Str("method", "Run").Logger()
+
+	now := time.Now()
+	for _, e := range daemon.entries {
+		daemon.schedule(e, now)
+	}
+	for {
+		var e *entry
+		for _, candidate := range daemon.entries {
+			if !candidate.next.IsZero() && (e == nil || candidate.next.Before(e.next)) {
+				e = candidate
+			}
+		}
+		if e == nil {
+			l.Error().Msg("Ningún horario tiene corridas futuras")
+			return common.NewNotFoundError("una corrida futura en daemon.schedules")
+		}
+
+		timer := time.NewTimer(time.Until(e.next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			l.Info().Msg("Deteniendo daemon")
+			return nil
+		case <-timer.C:
+		}
+
+		done := make(chan struct{})
+		go func() {
+			daemon.run(e)
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-ctx.Done():
+			l.Info().Str("kind", e.kind).Msg("Esperando que termine la corrida en curso antes de detener el daemon...")
+			<-done
+			l.Info().Msg("Deteniendo daemon")
+			return nil
+		}
+		daemon.schedule(e, time.Now())
+	}
+}
+
+func (daemon *Daemon) run(e *entry) {
+	l := daemon.logger.With().Str("method", "run").Str("kind", e.kind).Logger()
+
+	start := time.Now()
+	l.Info().Msg("Iniciando corrida")
+	err := e.job()
+	var locked *common.LockedError
+	if errors.As(err, &locked) {
+		l.Warn().Str("lock", daemon.config.LockFile).Msg("Otra corrida tiene el lock, saltando...")
+		return
+	}
+	if err != nil {
+		e.failures++
+		l.Error().Err(err).Int("fallas", e.failures).Msg("Corrida fallida")
+		return
+	}
+	e.failures = 0
+	l.Info().Dur("duracion", time.Since(start)).Msg("Corrida terminada")
+}
@@ -0,0 +1,100 @@
+package daemon
+
+import (
+	"errors"
+	"testing"
+	"time"
+	"webscraping/common"
+
+	"github.com/rs/zerolog"
+)
+
+func newTestDaemon(t *testing.T, config Config, job Job) (*Daemon, *entry) {
+	config.Schedules = map[string]string{"languages": "* * * * *", "tags": ""}
+	daemon, err := NewDaemon(config, map[string]Job{"languages": job, "tags": job}, zerolog.Nop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(daemon.entries) != 1 {
+		t.Fatalf("got %v entries, want only languages", len(daemon.entries))
+	}
+	return daemon, daemon.entries[0]
+}
+
+func TestScheduleBackoff(t *testing.T) {
+	daemon, e := newTestDaemon(t, Config{BackoffSeconds: 60, MaxBackoffSeconds: 300}, func() error { return nil })
+	now := time.Date(2024, 1, 1, 10, 0, 30, 0, time.UTC)
+
+	tests := []struct {
+		failures int
+		want     time.Time
+	}{
+		{0, time.Date(2024, 1, 1, 10, 1, 0, 0, time.UTC)},
+		// 60s: desde 10:01:30
+		{1, time.Date(2024, 1, 1, 10, 2, 0, 0, time.UTC)},
+		// 120s: desde 10:02:30
+		{2, time.Date(2024, 1, 1, 10, 3, 0, 0, time.UTC)},
+		// 240s: desde 10:04:30
+		{3, time.Date(2024, 1, 1, 10, 5, 0, 0, time.UTC)},
+		// 480s limitado a 300s: desde 10:05:30
+		{4, time.Date(2024, 1, 1, 10, 6, 0, 0, time.UTC)},
+		{40, time.Date(2024, 1, 1, 10, 6, 0, 0, time.UTC)},
+	}
+	for _, test := range tests {
+		e.failures = test.failures
+		daemon.schedule(e, now)
+		if !e.next.Equal(test.want) {
+			t.Errorf("%d failures: next = %v, want %v", test.failures, e.next, test.want)
+		}
+	}
+}
+
+func TestScheduleJitter(t *testing.T) {
+	daemon, e := newTestDaemon(t, Config{JitterSeconds: 30}, func() error { return nil })
+	now := time.Date(2024, 1, 1, 10, 0, 30, 0, time.UTC)
+	base := time.Date(2024, 1, 1, 10, 1, 0, 0, time.UTC)
+	for i := 0; i < 100; i++ {
+		daemon.schedule(e, now)
+		if e.next.Before(base) || !e.next.Before(base.Add(30*time.Second)) {
+			t.Fatalf("next = %v, want within 30s after %v", e.next, base)
+		}
+	}
+}
+
+func TestRunFailures(t *testing.T) {
+	var err error
+	daemon, e := newTestDaemon(t, Config{}, func() error { return err })
+
+	err = errors.New("falla")
+	daemon.run(e)
+	daemon.run(e)
+	if e.failures != 2 {
+		t.Errorf("failures = %v, want 2", e.failures)
+	}
+
+	err = common.NewLockedError("webscraping.lock")
+	daemon.run(e)
+	if e.failures != 2 {
+		t.Errorf("a locked run should not count as failure, failures = %v", e.failures)
+	}
+
+	err = nil
+	daemon.run(e)
+	if e.failures != 0 {
+		t.Errorf("a successful run should reset failures, failures = %v", e.failures)
+	}
+}
+
+func TestNewDaemonInvalid(t *testing.T) {
+	jobs := map[string]Job{"languages": func() error { return nil }}
+	tests := []map[string]string{
+		{"languages": "not a schedule"},
+		{"repos": "* * * * *"},
+		{"languages": ""},
+	}
+	for _, schedules := range tests {
+		if _, err := NewDaemon(Config{Schedules: schedules}, jobs, zerolog.Nop()); err == nil {
+			t.Errorf("%v: expected error", schedules)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package daemon
+
+import (
+	"os"
+	"strconv"
+)
+
+// Lock de archivo para que dos corridas nunca se superpongan, aunque sean de procesos distintos
+type Lock struct {
+	filename string
+	file     *os.File
+}
+
+func NewLock(filename string) *Lock {
+	return &Lock{filename: filename}
+}
+
+// Toma el lock sin esperar. Retorna false si otro proceso lo tiene.
+func (lock *Lock) TryLock() (bool, error) {
+	file, err := os.OpenFile(lock.filename, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return false, err
+	}
+	locked, err := tryLock(file)
+	if err != nil || !locked {
+		file.Close()
+		return false, err
+	}
+	// El pid es solo informativo, para saber quién tiene el lock
+	file.Truncate(0)
+	file.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	lock.file = file
+	return true, nil
+}
+
+func (lock *Lock) Unlock() error {
+	if lock.file == nil {
+		return nil
+	}
+	file := lock.file
+	lock.file = nil
+	file.Truncate(0)
+	if err := unlock(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,32 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd
+// +build !linux,!darwin,!freebsd,!netbsd,!openbsd
+
+package daemon
+
+import (
+	"os"
+	"sync"
+)
+
+// Sin flock solo se evita la superposición dentro del mismo proceso
+var (
+	mutex  sync.Mutex
+	locked = make(map[string]bool)
+)
+
+func tryLock(file *os.File) (bool, error) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	if locked[file.Name()] {
+		return false, nil
+	}
+	locked[file.Name()] = true
+	return true, nil
+}
+
+func unlock(file *os.File) error {
+	mutex.Lock()
+	defer mutex.Unlock()
+	delete(locked, file.Name())
+	return nil
+}
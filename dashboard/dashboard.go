@@ -0,0 +1,238 @@
+package dashboard
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"html/template"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+	"webscraping/common"
+	"webscraping/export"
+	"webscraping/scraping"
+	"webscraping/staticchart"
+
+	"github.com/rs/zerolog"
+)
+
+// Plantillas por defecto. Cualquier plantilla con nombre se puede reemplazar desde template_dir.
+//
+//go:embed templates/*.html
+var defaultTemplates embed.FS
+
+type Config struct {
+	Enabled  bool   `json:"enabled" yaml:"enabled"`
+	HtmlFile string `json:"html_file" yaml:"html_file"`
+	// Donde se guarda la última sección de cada ejercicio para armar el reporte combinado
+	Directory   string `json:"directory" yaml:"directory"`
+	TemplateDir string `json:"template_dir" yaml:"template_dir"`
+}
+
+// Resultado de un ejercicio dentro del reporte
+type Section struct {
+	Kind  string        `json:"kind"`
+	Title string        `json:"title"`
+	Table *export.Table `json:"table"`
+	// Gráfica svg incluida en la página, para que no dependa de javascript
+	SVG string `json:"svg"`
+	// Gráfica html interactiva del ejercicio
+	ChartFile string                `json:"chart_file"`
+	Scrape    scraping.ScrapeReport `json:"scrape"`
+	// Líneas adicionales del ejercicio, como el reporte de paginación o la correlación
+	Notes   []string `json:"notes"`
+	Exports []string `json:"exports"`
+}
+
+type page struct {
+	Generated time.Time
+	Sections  []Section
+	// Rutas relativas al html, para que los links funcionen al mover la carpeta
+	base string
+}
+
+func GetDefaultConfig() Config {
+	return Config{
+		Enabled:     true,
+		HtmlFile:    "reporte.html",
+		Directory:   "reporte",
+		TemplateDir: "",
+	}
+}
+
+// El servidor comparte los archivos de directory, así que no puede ser la carpeta de trabajo ni la raíz,
+// donde están la configuración y el historial
+func (config *Config) Validate() error {
+	dir := filepath.Clean(config.Directory)
+	if dir == "." || dir == filepath.Dir(dir) {
+		return common.NewConfigError("dashboard.directory", config.Directory)
+	}
+	return nil
+}
+
+// Dibuja la gráfica como svg para incluirla en la sección
+func (section *Section) SetChart(chart staticchart.Chart, config staticchart.Config) error {
+	var buffer bytes.Buffer
+	if err := staticchart.Encode(chart, staticchart.FormatSVG, config.Width, config.Height, &buffer); err != nil {
+		return err
+	}
+	section.SVG = buffer.String()
+	return nil
+}
+
+// Guarda la sección y vuelve a generar el reporte con la última sección de cada ejercicio
+func Update(section Section, config Config, logger zerolog.Logger) error {
+	l := logger.With().Str("function", "Update").Str("kind", section.Kind).Logger()
+
+	l.Trace().Str("dir", config.Directory).Msg("Guardando sección")
+	if err := os.MkdirAll(config.Directory, 0755); err != nil {
+		l.Error().Err(err).Msg("No se pudo crear directorio del reporte")
+		return err
+	}
+	if section.ChartFile != "" {
+		// Copia de la gráfica interactiva, porque ambos ejercicios la guardan en el mismo archivo
+		chart, err := os.ReadFile(section.ChartFile)
+		if err != nil {
+			l.Warn().Err(err).Msg("No se pudo leer la gráfica interactiva")
+			section.ChartFile = ""
+		} else {
+			section.ChartFile = filepath.Join(config.Directory, section.Kind+".html")
+			if err = os.WriteFile(section.ChartFile, chart, 0644); err != nil {
+				l.Error().Err(err).Msg("No se pudo copiar la gráfica interactiva")
+				return err
+			}
+		}
+	}
+	content, err := json.MarshalIndent(section, "", "  ")
+	if err != nil {
+		l.Error().Err(err).Msg("No se pudo serializar sección")
+		return err
+	}
+	if err = os.WriteFile(filepath.Join(config.Directory, section.Kind+".json"), content, 0644); err != nil {
+		l.Error().Err(err).Msg("No se pudo guardar sección")
+		return err
+	}
+	return Build(config, logger)
+}
+
+func loadSections(directory string) ([]Section, error) {
+	files, err := filepath.Glob(filepath.Join(directory, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	var sections []Section
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var section Section
+		if err = json.Unmarshal(content, &section); err != nil {
+			return nil, err
+		}
+		sections = append(sections, section)
+	}
+	return sections, nil
+}
+
+// Última sección guardada del ejercicio
+func LoadSection(config Config, kind string) (*Section, error) {
+	content, err := os.ReadFile(filepath.Join(config.Directory, kind+".json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, common.NewNotFoundError("la sección " + kind)
+		}
+		return nil, err
+	}
+	var section Section
+	if err = json.Unmarshal(content, &section); err != nil {
+		return nil, err
+	}
+	return &section, nil
+}
+
+// Todas las secciones guardadas, ordenadas por tipo
+func LoadSections(config Config) ([]Section, error) {
+	return loadSections(config.Directory)
+}
+
+func templates(config Config, p *page) (*template.Template, error) {
+	funcs := template.FuncMap{
+		"value": formatValue,
+		"svg":   func(s string) template.HTML { return template.HTML(s) },
+		"link":  p.link,
+		"ms":    func(d time.Duration) string { return d.Round(time.Millisecond).String() },
+		"date":  func(t time.Time) string { return t.Local().Format("2006-01-02 15:04:05") },
+	}
+	tmpl, err := template.New("dashboard.html").Funcs(funcs).ParseFS(defaultTemplates, "templates/*.html")
+	if err != nil {
+		return nil, err
+	}
+	if config.TemplateDir != "" {
+		// Las plantillas con el mismo nombre reemplazan a las por defecto
+		tmpl, err = tmpl.ParseGlob(filepath.Join(config.TemplateDir, "*.html"))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return tmpl, nil
+}
+
+// Genera el reporte html con todas las secciones guardadas
+func Build(config Config, logger zerolog.Logger) error {
+	l := logger.With().Str("function", "Build").Logger()
+
+	l.Trace().Str("dir", config.Directory).Msg("Leyendo secciones")
+	sections, err := loadSections(config.Directory)
+	if err != nil {
+		l.Error().Err(err).Msg("No se pudieron leer las secciones del reporte")
+		return err
+	}
+	p := page{Generated: time.Now(), Sections: sections, base: filepath.Dir(config.HtmlFile)}
+
+	l.Trace().Msg("Cargando plantillas")
+	tmpl, err := templates(config, &p)
+	if err != nil {
+		l.Error().Err(err).Msg("No se pudieron cargar las plantillas")
+		return err
+	}
+
+	l.Trace().Str("html-file", config.HtmlFile).Msg("Crear archivo html")
+	var buffer bytes.Buffer
+	if err = tmpl.ExecuteTemplate(&buffer, "dashboard.html", &p); err != nil {
+		l.Error().Err(err).Msg("No se pudo generar el reporte")
+		return err
+	}
+	return os.WriteFile(config.HtmlFile, buffer.Bytes(), 0644)
+}
+
+func (p *page) link(file string) string {
+	if rel, err := filepath.Rel(p.base, file); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(file)
+}
+
+// Los valores vienen de json, así que los enteros llegan como float64
+func formatValue(value interface{}) string {
+	f, ok := value.(float64)
+	if !ok {
+		return toString(value)
+	}
+	if f == math.Trunc(f) && math.Abs(f) < 1e15 {
+		return strconv.FormatInt(int64(f), 10)
+	}
+	return strconv.FormatFloat(f, 'f', 2, 64)
+}
+
+func toString(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	content, _ := json.Marshal(value)
+	return string(content)
+}
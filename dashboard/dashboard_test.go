@@ -0,0 +1,14 @@
+package dashboard
+
+import "testing"
+
+func TestValidateDirectory(t *testing.T) {
+	tests := map[string]bool{"reporte": true, "salida/reporte": true, "": false, ".": false, "./": false, "/": false}
+	for directory, valid := range tests {
+		config := GetDefaultConfig()
+		config.Directory = directory
+		if err := config.Validate(); (err == nil) != valid {
+			t.Errorf("%q: got %v, want valid %v", directory, err, valid)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package export
+
+import (
+	"encoding/csv"
+	"os"
+)
+
+type CSVExporter struct{}
+
+func (CSVExporter) Name() string      { return "csv" }
+func (CSVExporter) Extension() string { return ".csv" }
+
+// Los metadatos se repiten como columnas en cada fila, así se pueden concatenar exportaciones de varias corridas
+func (CSVExporter) Export(file *os.File, table *Table) error {
+	writer := csv.NewWriter(file)
+	fields := table.Metadata.Fields()
+
+	header := append([]string{}, table.Columns...)
+	for _, field := range fields {
+		header = append(header, field[0])
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for _, row := range table.Rows {
+		record := make([]string, 0, len(header))
+		for _, value := range row {
+			record = append(record, formatValue(value))
+		}
+		for _, field := range fields {
+			record = append(record, field[1])
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
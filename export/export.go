@@ -0,0 +1,125 @@
+package export
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"webscraping/common"
+
+	"github.com/rs/zerolog"
+)
+
+// Un Exporter escribe una tabla de resultados en un formato de archivo
+type Exporter interface {
+	Name() string
+	Extension() string
+	Export(file *os.File, table *Table) error
+}
+
+type Metadata struct {
+	RunID      string    `json:"run_id,omitempty" yaml:"run_id,omitempty"`
+	Kind       string    `json:"kind" yaml:"kind"`
+	Timestamp  time.Time `json:"timestamp" yaml:"timestamp"`
+	ConfigHash string    `json:"config_hash,omitempty" yaml:"config_hash,omitempty"`
+	Scorer     string    `json:"scorer,omitempty" yaml:"scorer,omitempty"`
+	Interest   string    `json:"interest,omitempty" yaml:"interest,omitempty"`
+	Sort       string    `json:"sort,omitempty" yaml:"sort,omitempty"`
+}
+
+type Table struct {
+	Metadata Metadata
+	Columns  []string
+	// Cada fila tiene un valor por columna: string, int, int32 o float64
+	Rows [][]interface{}
+}
+
+var exporters = map[string]Exporter{
+	"csv":      CSVExporter{},
+	"json":     JSONExporter{},
+	"markdown": MarkdownExporter{},
+	"yaml":     YAMLExporter{},
+	"xlsx":     XLSXExporter{},
+}
+
+func GetExporter(format string) (Exporter, error) {
+	exporter, ok := exporters[strings.ToLower(format)]
+	if !ok {
+		return nil, common.NewConfigError("formatos_exportacion", format)
+	}
+	return exporter, nil
+}
+
+// Pares clave valor no vacíos de los metadatos, en orden fijo
+func (meta *Metadata) Fields() [][2]string {
+	all := [][2]string{
+		{"run_id", meta.RunID},
+		{"kind", meta.Kind},
+		{"timestamp", meta.Timestamp.Format(time.RFC3339)},
+		{"config_hash", meta.ConfigHash},
+		{"scorer", meta.Scorer},
+		{"interest", meta.Interest},
+		{"sort", meta.Sort},
+	}
+	var fields [][2]string
+	for _, field := range all {
+		if field[1] != "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// Filas como mapas columna -> valor, para los formatos con objetos
+func (table *Table) Records() []map[string]interface{} {
+	records := make([]map[string]interface{}, 0, len(table.Rows))
+	for _, row := range table.Rows {
+		record := make(map[string]interface{})
+		for i, column := range table.Columns {
+			record[column] = row[i]
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func formatValue(value interface{}) string {
+	if f, ok := value.(float64); ok {
+		return fmt.Sprintf("%.4f", f)
+	}
+	return fmt.Sprint(value)
+}
+
+// Exporta la tabla en cada formato, usando base más la extensión del formato como nombre
+func ExportAll(base string, formats []string, table *Table, logger zerolog.Logger) ([]string, error) {
+	l := logger.With().Str("function", "ExportAll").Logger()
+
+	var files []string
+	var lastError error
+	for _, format := range formats {
+		exporter, err := GetExporter(format)
+		if err != nil {
+			l.Error().Err(err).Msg("Formato de exportación no soportado! Use csv, json, markdown, yaml o xlsx")
+			lastError = err
+			continue
+		}
+		filename := base + exporter.Extension()
+		l.Trace().Str("file", filename).Msg("Abriendo archivo")
+		file, err := os.Create(filename)
+		if err != nil {
+			l.Error().Err(err).Msg("No se pudo abrir ni crear archivo!")
+			lastError = err
+			continue
+		}
+		l.Trace().Str("formato", exporter.Name()).Msg("Exportando resultados")
+		err = exporter.Export(file, table)
+		file.Close()
+		if err != nil {
+			l.Error().Err(err).Str("file", filename).Msg("No se pudo exportar")
+			lastError = err
+			continue
+		}
+		files = append(files, filename)
+	}
+	return files, lastError
+}
@@ -0,0 +1,26 @@
+package export
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+)
+
+type JSONExporter struct{}
+
+func (JSONExporter) Name() string      { return "json" }
+func (JSONExporter) Extension() string { return ".json" }
+
+func (JSONExporter) Export(file *os.File, table *Table) error {
+	return WriteJSON(file, table)
+}
+
+// Escribe la tabla como {metadata, results}, igual que el archivo exportado
+func WriteJSON(w io.Writer, table *Table) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(struct {
+		Metadata Metadata                 `json:"metadata"`
+		Results  []map[string]interface{} `json:"results"`
+	}{table.Metadata, table.Records()})
+}
@@ -0,0 +1,31 @@
+package export
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+type MarkdownExporter struct{}
+
+func (MarkdownExporter) Name() string      { return "markdown" }
+func (MarkdownExporter) Extension() string { return ".md" }
+
+func (MarkdownExporter) Export(file *os.File, table *Table) error {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# Resultados %v\n\n", table.Metadata.Kind))
+	for _, field := range table.Metadata.Fields() {
+		sb.WriteString(fmt.Sprintf("- **%v**: %v\n", field[0], field[1]))
+	}
+	sb.WriteString("\n| " + strings.Join(table.Columns, " | ") + " |\n")
+	sb.WriteString("|" + strings.Repeat(" --- |", len(table.Columns)) + "\n")
+	for _, row := range table.Rows {
+		cells := make([]string, 0, len(row))
+		for _, value := range row {
+			cells = append(cells, strings.ReplaceAll(formatValue(value), "|", "\\|"))
+		}
+		sb.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+	}
+	_, err := file.WriteString(sb.String())
+	return err
+}
@@ -0,0 +1,118 @@
+package export
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Escribe un libro xlsx mínimo a mano: una hoja con los resultados y otra con los metadatos
+type XLSXExporter struct{}
+
+func (XLSXExporter) Name() string      { return "xlsx" }
+func (XLSXExporter) Extension() string { return ".xlsx" }
+
+const xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>
+<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>
+<Override PartName="/xl/worksheets/sheet2.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>
+</Types>`
+
+const xlsxRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>`
+
+const xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets>
+<sheet name="resultados" sheetId="1" r:id="rId1"/>
+<sheet name="metadatos" sheetId="2" r:id="rId2"/>
+</sheets>
+</workbook>`
+
+const xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet2.xml"/>
+</Relationships>`
+
+func (XLSXExporter) Export(file *os.File, table *Table) error {
+	results := [][]interface{}{toInterfaces(table.Columns)}
+	results = append(results, table.Rows...)
+	metadata := [][]interface{}{{"clave", "valor"}}
+	for _, field := range table.Metadata.Fields() {
+		metadata = append(metadata, []interface{}{field[0], field[1]})
+	}
+
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRels},
+		{"xl/workbook.xml", xlsxWorkbook},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/worksheets/sheet1.xml", xlsxSheet(results)},
+		{"xl/worksheets/sheet2.xml", xlsxSheet(metadata)},
+	}
+
+	archive := zip.NewWriter(file)
+	for _, part := range parts {
+		writer, err := archive.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err = writer.Write([]byte(part.content)); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
+
+func toInterfaces(values []string) []interface{} {
+	ret := make([]interface{}, 0, len(values))
+	for _, value := range values {
+		ret = append(ret, value)
+	}
+	return ret
+}
+
+func xlsxSheet(rows [][]interface{}) string {
+	var sb strings.Builder
+	sb.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+	sb.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	for i, row := range rows {
+		sb.WriteString(fmt.Sprintf(`<row r="%d">`, i+1))
+		for j, value := range row {
+			ref := fmt.Sprintf("%v%d", xlsxColumn(j), i+1)
+			switch v := value.(type) {
+			case int, int32, int64, float64:
+				sb.WriteString(fmt.Sprintf(`<c r="%v"><v>%v</v></c>`, ref, v))
+			default:
+				sb.WriteString(fmt.Sprintf(`<c r="%v" t="inlineStr"><is><t>%v</t></is></c>`, ref, xlsxEscape(fmt.Sprint(v))))
+			}
+		}
+		sb.WriteString("</row>")
+	}
+	sb.WriteString("</sheetData></worksheet>")
+	return sb.String()
+}
+
+// Nombre de columna de planilla: 0 -> A, 25 -> Z, 26 -> AA
+func xlsxColumn(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
+
+func xlsxEscape(text string) string {
+	var sb strings.Builder
+	xml.EscapeText(&sb, []byte(text))
+	return sb.String()
+}
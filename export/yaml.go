@@ -0,0 +1,23 @@
+package export
+
+import (
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+type YAMLExporter struct{}
+
+func (YAMLExporter) Name() string      { return "yaml" }
+func (YAMLExporter) Extension() string { return ".yaml" }
+
+func (YAMLExporter) Export(file *os.File, table *Table) error {
+	encoder := yaml.NewEncoder(file)
+	if err := encoder.Encode(struct {
+		Metadata Metadata                 `yaml:"metadata"`
+		Results  []map[string]interface{} `yaml:"results"`
+	}{table.Metadata, table.Records()}); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
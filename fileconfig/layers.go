@@ -0,0 +1,272 @@
+package fileconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"regexp"
+	"strings"
+	"webscraping/common"
+
+	"github.com/rs/zerolog"
+	"gopkg.in/yaml.v3"
+)
+
+// Prefijo de las variables de entorno que sobreescriben la configuración
+const EnvPrefix = "WEBSCRAPING_"
+
+// Origen de los valores que no vienen de un archivo ni de una variable de entorno
+const (
+	SourceDefault = "default"
+	SourceSet     = "--set"
+)
+
+var envName = regexp.MustCompile(`^[A-Z0-9_]+$`)
+
+// Un valor de la configuración efectiva y la capa de donde salió
+type Setting struct {
+	Path   string
+	Value  string
+	Source string
+}
+
+// Arma la configuración por capas: valores por defecto, archivos, variables de entorno y --set.
+// Cada capa pisa a las anteriores y se recuerda el origen de cada valor.
+type Layers struct {
+	logger  zerolog.Logger
+	sources map[string]string
+}
+
+// Las capas arrancan con los valores por defecto que ya tenga configData
+func NewLayers(logger zerolog.Logger, configData interface{}) (*Layers, error) {
+	layers := Layers{logger: logger, sources: make(map[string]string)}
+	settings, err := Flatten(configData)
+	if err != nil {
+		return nil, err
+	}
+	for _, setting := range settings {
+		layers.sources[setting.Path] = SourceDefault
+	}
+	return &layers, nil
+}
+
+// Aplica un archivo de configuración. Si el primero no existe se crea con los valores por defecto; los
+// siguientes tienen que existir. Los archivos existentes solo se leen, nunca se vuelven a guardar.
+func (layers *Layers) File(configData interface{}, filename string, first bool) error {
+	l := layers.logger.With().Str("struct", "Layers").Str("method", "File").Str("file", filename).Logger()
+
+	data, err := os.ReadFile(filename)
+	if first && os.IsNotExist(err) {
+		l.Info().Msg("Archivo de configuración no existe. Creando...")
+		return NewFileConfigstore(layers.logger, filename).Save(configData)
+	}
+	if err != nil {
+		l.Error().Err(err).Msg("No se pudo leer archivo de configuración")
+		return err
+	}
+
+	l.Info().Msg("Cargando configuración del archivo")
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		l.Error().Err(err).Msg("No se pudo leer archivo de configuración")
+		return err
+	}
+	if len(doc.Content) == 0 {
+		return nil
+	}
+	if err := doc.Decode(configData); err != nil {
+		l.Error().Err(err).Msg("No se pudo leer archivo de configuración")
+		return err
+	}
+
+	l.Trace().Msg("Registrando valores del archivo")
+	layers.mark(walk(doc.Content[0], ""), "archivo "+filename)
+	return nil
+}
+
+// Aplica las variables WEBSCRAPING_* de environ. El nombre se arma con la ruta del valor en mayúsculas
+// y con _ en lugar de puntos, por ejemplo WEBSCRAPING_SCRAPER_MAX_PARALLEL para scraper.max_parallel.
+func (layers *Layers) Env(configData interface{}, environ []string) error {
+	l := layers.logger.With().Str("struct", "Layers").Str("method", "Env").Logger()
+
+	values := make(map[string]string)
+	for _, variable := range environ {
+		name, value, found := cut(variable, "=")
+		if found && strings.HasPrefix(name, EnvPrefix) {
+			values[name] = value
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+
+	settings, err := Flatten(configData)
+	if err != nil {
+		return err
+	}
+	for _, setting := range settings {
+		name := EnvPrefix + strings.ToUpper(strings.ReplaceAll(setting.Path, ".", "_"))
+		value, ok := values[name]
+		if !ok || !envName.MatchString(name) {
+			continue
+		}
+		delete(values, name)
+		l.Debug().Str("env", name).Str("path", setting.Path).Msg("Aplicando variable de entorno")
+		if err := layers.apply(configData, setting.Path, value, "env "+name); err != nil {
+			return err
+		}
+	}
+	for name := range values {
+		l.Warn().Str("env", name).Msg("Variable de entorno sin valor de configuración correspondiente, ignorando")
+	}
+	return nil
+}
+
+// Aplica asignaciones ruta=valor, por ejemplo scraper.max_parallel=4. El valor se lee como yaml.
+func (layers *Layers) Set(configData interface{}, assignments []string) error {
+	l := layers.logger.With().Str("struct", "Layers").Str("method", "Set").Logger()
+
+	for _, assignment := range assignments {
+		path, value, found := cut(assignment, "=")
+		path = strings.TrimSpace(path)
+		if !found || path == "" {
+			l.Error().Str("set", assignment).Msg("Se esperaba ruta=valor")
+			return common.NewConfigError("--set", assignment)
+		}
+		l.Debug().Str("path", path).Msg("Aplicando --set")
+		if err := layers.apply(configData, path, value, SourceSet); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Configuración efectiva con el origen de cada valor, en el orden de la configuración
+func (layers *Layers) Effective(configData interface{}) ([]Setting, error) {
+	settings, err := Flatten(configData)
+	if err != nil {
+		return nil, err
+	}
+	for i := range settings {
+		settings[i].Source = layers.source(settings[i].Path)
+	}
+	return settings, nil
+}
+
+// Escribe value en path como un documento yaml anidado. Si value no es yaml válido (por ejemplo @daily)
+// se toma como texto. Las claves desconocidas son un error.
+func (layers *Layers) apply(configData interface{}, path, value, source string) error {
+	node := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(value), &doc); err == nil && len(doc.Content) > 0 {
+		node = doc.Content[0]
+	}
+	keys := strings.Split(path, ".")
+	for i := len(keys) - 1; i >= 0; i-- {
+		node = &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{{Kind: yaml.ScalarNode, Value: keys[i]}, node}}
+	}
+	data, err := yaml.Marshal(node)
+	if err != nil {
+		return err
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(configData); err != nil {
+		layers.logger.Error().Str("struct", "Layers").Str("method", "apply").Str("path", path).Err(err).Msg("No se pudo aplicar el valor")
+		return common.NewConfigError(path, value)
+	}
+	layers.mark(append(walk(node, ""), path), source)
+	return nil
+}
+
+func (layers *Layers) mark(paths []string, source string) {
+	for _, path := range paths {
+		layers.sources[path] = source
+	}
+}
+
+// El origen de una ruta es el de la ruta más larga registrada que la contiene. Así una lista o un mapa
+// escrito entero en una capa cuenta para todos sus valores.
+func (layers *Layers) source(path string) string {
+	for {
+		if source, ok := layers.sources[path]; ok {
+			return source
+		}
+		i := strings.LastIndex(path, ".")
+		if i < 0 {
+			return SourceDefault
+		}
+		path = path[:i]
+	}
+}
+
+// Lista los valores hoja de configData. Las listas y los mapas vacíos cuentan como un solo valor.
+func Flatten(configData interface{}) ([]Setting, error) {
+	data, err := yaml.Marshal(configData)
+	if err != nil {
+		return nil, err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+	var settings []Setting
+	var visit func(node *yaml.Node, path string)
+	visit = func(node *yaml.Node, path string) {
+		if node.Kind == yaml.MappingNode && len(node.Content) > 0 {
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				visit(node.Content[i+1], join(path, node.Content[i].Value))
+			}
+			return
+		}
+		settings = append(settings, Setting{Path: path, Value: render(node)})
+	}
+	visit(doc.Content[0], "")
+	return settings, nil
+}
+
+// Rutas de todos los valores hoja de un nodo
+func walk(node *yaml.Node, path string) []string {
+	if node.Kind == yaml.MappingNode && len(node.Content) > 0 {
+		var paths []string
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			paths = append(paths, walk(node.Content[i+1], join(path, node.Content[i].Value))...)
+		}
+		return paths
+	}
+	return []string{path}
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// Escalares tal cual, listas y mapas en una línea
+func render(node *yaml.Node) string {
+	if node.Kind == yaml.ScalarNode {
+		return node.Value
+	}
+	var value interface{}
+	if err := node.Decode(&value); err != nil {
+		return ""
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// strings.Cut no existe en go 1.17
+func cut(s, sep string) (string, string, bool) {
+	if i := strings.Index(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
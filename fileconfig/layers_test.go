@@ -0,0 +1,109 @@
+package fileconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+type testConfig struct {
+	Name   string `yaml:"name"`
+	Server struct {
+		Address string `yaml:"address"`
+		Port    int    `yaml:"port"`
+	} `yaml:"server"`
+}
+
+func defaultTestConfig() *testConfig {
+	config := testConfig{Name: "webscraping"}
+	config.Server.Address = "localhost"
+	config.Server.Port = 8080
+	return &config
+}
+
+func sources(t *testing.T, layers *Layers, config *testConfig) map[string]string {
+	settings, err := layers.Effective(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]string)
+	for _, setting := range settings {
+		got[setting.Path] = setting.Source
+	}
+	return got
+}
+
+func TestFileReadOnly(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "app.config")
+	content := "server:\n  port: 9000\n"
+	if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config := defaultTestConfig()
+	layers, err := NewLayers(zerolog.Nop(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := layers.File(config, filename, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := layers.Set(config, []string{"name=otro"}); err != nil {
+		t.Fatal(err)
+	}
+	if config.Server.Port != 9000 || config.Server.Address != "localhost" || config.Name != "otro" {
+		t.Errorf("unexpected config %+v", config)
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != content {
+		t.Errorf("the file should not be rewritten, got:\n%s", data)
+	}
+
+	got := sources(t, layers, config)
+	want := map[string]string{"name": SourceSet, "server.address": SourceDefault, "server.port": "archivo " + filename}
+	for path, source := range want {
+		if got[path] != source {
+			t.Errorf("%v: source = %q, want %q", path, got[path], source)
+		}
+	}
+}
+
+func TestFileCreatesMissing(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "config", "app.config")
+
+	config := defaultTestConfig()
+	layers, err := NewLayers(zerolog.Nop(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := layers.File(config, filepath.Join(dir, "extra.config"), false); err == nil {
+		t.Error("a missing file other than the first should be an error")
+	}
+	if err := layers.File(config, filename, true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filename); err != nil {
+		t.Fatalf("the first file should be created: %v", err)
+	}
+	for path, source := range sources(t, layers, config) {
+		if source != SourceDefault {
+			t.Errorf("%v: source = %q, want default", path, source)
+		}
+	}
+
+	// Al volver a leerlo se obtienen los mismos valores
+	loaded := &testConfig{}
+	if err := layers.File(loaded, filename, true); err != nil {
+		t.Fatal(err)
+	}
+	if *loaded != *defaultTestConfig() {
+		t.Errorf("got %+v, want the defaults", loaded)
+	}
+}
@@ -0,0 +1,163 @@
+package forecast
+
+import (
+	"math"
+	"strings"
+	"webscraping/common"
+)
+
+type Config struct {
+	Model string `json:"model" yaml:"model"`
+	Weeks int    `json:"weeks" yaml:"weeks"`
+	// Parámetros de suavizado de Holt para nivel y tendencia
+	Alpha float64 `json:"alpha" yaml:"alpha"`
+	Beta  float64 `json:"beta" yaml:"beta"`
+	// Valor z del intervalo de predicción (1.96 para 95%)
+	Z float64 `json:"z" yaml:"z"`
+}
+
+type Prediction struct {
+	// Días desde la primera observación
+	Time                float64
+	Value, Lower, Upper float64
+}
+
+// Un Forecaster proyecta una serie observada en los tiempos t (en días) hasta los tiempos at
+type Forecaster interface {
+	Name() string
+	Forecast(t, y, at []float64) ([]Prediction, error)
+}
+
+type LinearForecaster struct {
+	Z float64
+}
+
+type HoltForecaster struct {
+	Alpha, Beta, Z float64
+}
+
+func GetDefaultConfig() Config {
+	return Config{
+		Model: "linear",
+		Weeks: 4,
+		Alpha: 0.5,
+		Beta:  0.3,
+		Z:     1.96,
+	}
+}
+
+func GetForecaster(config Config) (Forecaster, error) {
+	switch strings.ToLower(config.Model) {
+	case "linear":
+		return LinearForecaster{Z: config.Z}, nil
+	case "holt":
+		return HoltForecaster{Alpha: config.Alpha, Beta: config.Beta, Z: config.Z}, nil
+	}
+	return nil, common.NewConfigError("forecast.model", config.Model)
+}
+
+func (LinearForecaster) Name() string { return "linear" }
+
+// Regresión por mínimos cuadrados con intervalo de predicción para una nueva observación
+func (f LinearForecaster) Forecast(t, y, at []float64) ([]Prediction, error) {
+	n := float64(len(t))
+	if len(t) < 2 || len(t) != len(y) {
+		return nil, common.NewParseError("serie con al menos 2 observaciones")
+	}
+	var tmean, ymean float64
+	for i := range t {
+		tmean += t[i]
+		ymean += y[i]
+	}
+	tmean /= n
+	ymean /= n
+	var stt, sty float64
+	for i := range t {
+		stt += (t[i] - tmean) * (t[i] - tmean)
+		sty += (t[i] - tmean) * (y[i] - ymean)
+	}
+	slope := 0.0
+	if stt > 0 {
+		slope = sty / stt
+	}
+	intercept := ymean - slope*tmean
+
+	// Error estándar de los residuos. Con 2 observaciones la recta pasa por ambas y no hay grados de
+	// libertad para estimarlo, así que el intervalo es infinito.
+	var sse float64
+	for i := range t {
+		residual := y[i] - (intercept + slope*t[i])
+		sse += residual * residual
+	}
+	s := math.Inf(1)
+	if len(t) > 2 {
+		s = math.Sqrt(sse / (n - 2))
+	}
+
+	predictions := make([]Prediction, 0, len(at))
+	for _, x := range at {
+		value := intercept + slope*x
+		spread := 1 + 1/n
+		if stt > 0 {
+			spread += (x - tmean) * (x - tmean) / stt
+		}
+		margin := interval(f.Z, s, spread)
+		predictions = append(predictions, Prediction{Time: x, Value: value, Lower: value - margin, Upper: value + margin})
+	}
+	return predictions, nil
+}
+
+func (HoltForecaster) Name() string { return "holt" }
+
+// Suavizado exponencial doble. Las corridas no son equiespaciadas, así que se usa el
+// intervalo promedio como paso y el horizonte se mide en pasos.
+func (f HoltForecaster) Forecast(t, y, at []float64) ([]Prediction, error) {
+	if len(t) < 2 || len(t) != len(y) {
+		return nil, common.NewParseError("serie con al menos 2 observaciones")
+	}
+	step := (t[len(t)-1] - t[0]) / float64(len(t)-1)
+	if step <= 0 {
+		step = 1
+	}
+
+	// El nivel y la tendencia iniciales salen de las dos primeras observaciones, así que el primer
+	// error de pronóstico a un paso es el de la tercera. Con 2 observaciones el intervalo es infinito.
+	level := y[1]
+	trend := y[1] - y[0]
+	var sse float64
+	for i := 2; i < len(y); i++ {
+		predicted := level + trend
+		sse += (y[i] - predicted) * (y[i] - predicted)
+		previous := level
+		level = f.Alpha*y[i] + (1-f.Alpha)*(level+trend)
+		trend = f.Beta*(level-previous) + (1-f.Beta)*trend
+	}
+	sigma := math.Inf(1)
+	if len(y) > 2 {
+		sigma = math.Sqrt(sse / float64(len(y)-2))
+	}
+
+	last := t[len(t)-1]
+	predictions := make([]Prediction, 0, len(at))
+	for _, x := range at {
+		h := (x - last) / step
+		value := level + h*trend
+		// Varianza del error a h pasos del modelo de Holt aditivo
+		variance := 1.0
+		for j := 1; float64(j) < h; j++ {
+			c := f.Alpha * (1 + float64(j)*f.Beta)
+			variance += c * c
+		}
+		margin := interval(f.Z, sigma, variance)
+		predictions = append(predictions, Prediction{Time: x, Value: value, Lower: value - margin, Upper: value + margin})
+	}
+	return predictions, nil
+}
+
+// Mitad del ancho del intervalo. Un error estándar infinito da un intervalo infinito aunque z sea 0.
+func interval(z, s, variance float64) float64 {
+	if math.IsInf(s, 1) {
+		return s
+	}
+	return z * s * math.Sqrt(variance)
+}
@@ -0,0 +1,129 @@
+package forecast
+
+import (
+	"math"
+	"testing"
+)
+
+const tolerance = 1e-6
+
+func near(a, b float64) bool {
+	if math.IsInf(a, 0) || math.IsInf(b, 0) {
+		return a == b
+	}
+	return math.Abs(a-b) < tolerance
+}
+
+func TestLinearForecast(t *testing.T) {
+	tests := []struct {
+		name                string
+		t, y, at            []float64
+		value, lower, upper float64
+	}{
+		{
+			name:  "recta exacta",
+			t:     []float64{0, 1, 2, 3},
+			y:     []float64{1, 3, 5, 7},
+			at:    []float64{4},
+			value: 9, lower: 9, upper: 9,
+		},
+		{
+			// pendiente 1/2, ordenada 1/6, s = sqrt(1/6), spread = 1 + 1/3 + 4/2
+			name:  "con residuos",
+			t:     []float64{0, 1, 2},
+			y:     []float64{0, 1, 1},
+			at:    []float64{3},
+			value: 5.0 / 3, lower: 5.0/3 - math.Sqrt(5.0/9), upper: 5.0/3 + math.Sqrt(5.0/9),
+		},
+		{
+			name:  "dos observaciones",
+			t:     []float64{0, 7},
+			y:     []float64{10, 24},
+			at:    []float64{14},
+			value: 38, lower: math.Inf(-1), upper: math.Inf(1),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			predictions, err := LinearForecaster{Z: 1}.Forecast(test.t, test.y, test.at)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := predictions[0]
+			if !near(got.Value, test.value) || !near(got.Lower, test.lower) || !near(got.Upper, test.upper) {
+				t.Errorf("got %v [%v, %v], want %v [%v, %v]", got.Value, got.Lower, got.Upper, test.value, test.lower, test.upper)
+			}
+		})
+	}
+}
+
+func TestHoltForecast(t *testing.T) {
+	// Nivel 12 y tendencia 2 iniciales. Errores a un paso: 15 - 14 = 1 y 17 - 16.65 = 0.35,
+	// nivel final 16.825, tendencia final 2.2025 y sigma = sqrt(1.1225 / 2).
+	sigma := math.Sqrt(1.1225 / 2)
+	tests := []struct {
+		name                string
+		t, y, at            []float64
+		value, lower, upper []float64
+	}{
+		{
+			name:  "valores conocidos",
+			t:     []float64{0, 7, 14, 21},
+			y:     []float64{10, 12, 15, 17},
+			at:    []float64{28, 35},
+			value: []float64{19.0275, 21.23},
+			lower: []float64{19.0275 - sigma, 21.23 - sigma*math.Sqrt(1.4225)},
+			upper: []float64{19.0275 + sigma, 21.23 + sigma*math.Sqrt(1.4225)},
+		},
+		{
+			name:  "recta exacta",
+			t:     []float64{0, 1, 2, 3},
+			y:     []float64{0, 1, 2, 3},
+			at:    []float64{5},
+			value: []float64{5},
+			lower: []float64{5},
+			upper: []float64{5},
+		},
+		{
+			name:  "dos observaciones",
+			t:     []float64{0, 7},
+			y:     []float64{10, 12},
+			at:    []float64{14},
+			value: []float64{14},
+			lower: []float64{math.Inf(-1)},
+			upper: []float64{math.Inf(1)},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			predictions, err := HoltForecaster{Alpha: 0.5, Beta: 0.3, Z: 1}.Forecast(test.t, test.y, test.at)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, got := range predictions {
+				if !near(got.Value, test.value[i]) || !near(got.Lower, test.lower[i]) || !near(got.Upper, test.upper[i]) {
+					t.Errorf("at %v: got %v [%v, %v], want %v [%v, %v]", got.Time, got.Value, got.Lower, got.Upper, test.value[i], test.lower[i], test.upper[i])
+				}
+			}
+		})
+	}
+}
+
+func TestForecastTooFewPoints(t *testing.T) {
+	for _, forecaster := range []Forecaster{LinearForecaster{Z: 1}, HoltForecaster{Alpha: 0.5, Beta: 0.3, Z: 1}} {
+		if _, err := forecaster.Forecast([]float64{0}, []float64{1}, []float64{7}); err == nil {
+			t.Errorf("%v: expected error with a single observation", forecaster.Name())
+		}
+	}
+}
+
+func TestGetForecaster(t *testing.T) {
+	for _, model := range []string{"linear", "Holt"} {
+		if _, err := GetForecaster(Config{Model: model}); err != nil {
+			t.Errorf("%v: %v", model, err)
+		}
+	}
+	if _, err := GetForecaster(Config{Model: "arima"}); err == nil {
+		t.Error("expected error for unknown model")
+	}
+}
@@ -0,0 +1,287 @@
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+	"webscraping/common"
+
+	"github.com/rs/zerolog"
+)
+
+// Tipos de corrida que se guardan en el historial
+const (
+	KindLanguages = "languages"
+	KindTags      = "tags"
+)
+
+// Métricas que se pueden leer de una corrida
+const (
+	MetricCounts = "counts"
+	MetricScores = "scores"
+)
+
+type Config struct {
+	Enabled   bool   `json:"enabled" yaml:"enabled"`
+	Directory string `json:"directory" yaml:"directory"`
+}
+
+type Run struct {
+	ID         string             `json:"id"`
+	Timestamp  time.Time          `json:"timestamp"`
+	Kind       string             `json:"kind"`
+	ConfigHash string             `json:"config_hash"`
+	Scorer     string             `json:"scorer,omitempty"`
+	Languages  map[string]int32   `json:"languages,omitempty"`
+	Scores     map[string]float64 `json:"scores,omitempty"`
+	TiobeRanks map[string]int     `json:"tiobe_ranks,omitempty"`
+	Interest   string             `json:"interest,omitempty"`
+	Sort       string             `json:"sort,omitempty"`
+	Tags       map[string]int     `json:"tags,omitempty"`
+	// Nombres sin valor en esta corrida por una consulta fallida o un valor anómalo descartado
+	Missing []string `json:"missing,omitempty"`
+}
+
+// Filtros de una consulta, los valores vacíos no filtran
+type Query struct {
+	From, To time.Time
+	Kind     string
+	Language string
+	Tag      string
+}
+
+type HistoryStore struct {
+	logger   zerolog.Logger
+	filename string
+	mutex    sync.Mutex
+}
+
+func GetDefaultConfig() Config {
+	return Config{
+		Enabled:   true,
+		Directory: "historial",
+	}
+}
+
+func NewHistoryStore(logger zerolog.Logger, directory string) *HistoryStore {
+	var store HistoryStore
+	store.logger = logger.With().Str("struct", "HistoryStore").Logger()
+	store.filename = filepath.Join(directory, "runs.jsonl")
+	return &store
+}
+
+func (store *HistoryStore) Append(run *Run) error {
+	l := store.logger.With().Str("method", "Append").Logger()
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if run.Timestamp.IsZero() {
+		run.Timestamp = time.Now()
+	}
+	if run.ID == "" {
+		run.ID = fmt.Sprintf("%v-%v", run.Timestamp.UTC().Format("20060102T150405.000"), run.Kind)
+	}
+
+	l.Trace().Str("file", store.filename).Msg("Creando directorio del historial")
+	if err := os.MkdirAll(filepath.Dir(store.filename), 0755); err != nil {
+		l.Error().Err(err).Msg("No se pudo crear directorio del historial")
+		return err
+	}
+
+	line, err := json.Marshal(run)
+	if err != nil {
+		l.Error().Err(err).Msg("No se pudo serializar corrida")
+		return err
+	}
+
+	l.Trace().Str("id", run.ID).Msg("Agregando corrida al historial")
+	file, err := os.OpenFile(store.filename, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		l.Error().Err(err).Msg("No se pudo abrir archivo del historial")
+		return err
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		l.Error().Err(err).Msg("No se pudo escribir en el historial")
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Todas las corridas ordenadas por fecha
+func (store *HistoryStore) Load() ([]Run, error) {
+	l := store.logger.With().Str("method", "Load").Logger()
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	file, err := os.Open(store.filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			l.Trace().Msg("Historial vacío")
+			return nil, nil
+		}
+		l.Error().Err(err).Msg("No se pudo abrir archivo del historial")
+		return nil, err
+	}
+	defer file.Close()
+
+	var runs []Run
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var run Run
+		if err := json.Unmarshal(scanner.Bytes(), &run); err != nil {
+			l.Warn().Err(err).Int("linea", line).Msg("Línea inválida en el historial, saltando...")
+			continue
+		}
+		runs = append(runs, run)
+	}
+	if err := scanner.Err(); err != nil {
+		l.Error().Err(err).Msg("No se pudo leer el historial")
+		return nil, err
+	}
+	sort.SliceStable(runs, func(i, j int) bool { return runs[i].Timestamp.Before(runs[j].Timestamp) })
+	return runs, nil
+}
+
+func (store *HistoryStore) Query(query Query) ([]Run, error) {
+	runs, err := store.Load()
+	if err != nil {
+		return nil, err
+	}
+	var ret []Run
+	for _, run := range runs {
+		if query.matches(&run) {
+			ret = append(ret, run)
+		}
+	}
+	return ret, nil
+}
+
+func (store *HistoryStore) Get(id string) (*Run, error) {
+	runs, err := store.Load()
+	if err != nil {
+		return nil, err
+	}
+	for i := range runs {
+		if runs[i].ID == id {
+			return &runs[i], nil
+		}
+	}
+	return nil, common.NewNotFoundError("la corrida " + id)
+}
+
+// Las últimas n corridas del tipo, de la más reciente a la más antigua
+func (store *HistoryStore) Latest(kind string, n int) ([]Run, error) {
+	runs, err := store.Query(Query{Kind: kind})
+	if err != nil {
+		return nil, err
+	}
+	var ret []Run
+	for i := len(runs) - 1; i >= 0 && len(ret) < n; i-- {
+		ret = append(ret, runs[i])
+	}
+	return ret, nil
+}
+
+func (query *Query) matches(run *Run) bool {
+	if query.Kind != "" && run.Kind != query.Kind {
+		return false
+	}
+	if !query.From.IsZero() && run.Timestamp.Before(query.From) {
+		return false
+	}
+	if !query.To.IsZero() && run.Timestamp.After(query.To) {
+		return false
+	}
+	if query.Language != "" {
+		if _, ok := run.Languages[query.Language]; !ok {
+			return false
+		}
+	}
+	if query.Tag != "" {
+		if _, ok := run.Tags[query.Tag]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Puntaje por lenguaje, o la cantidad si se pide MetricCounts o la corrida es de tags
+func (run *Run) Metric(metric string) map[string]float64 {
+	if metric != MetricScores || run.Kind == KindTags {
+		return run.Values()
+	}
+	values := make(map[string]float64)
+	for lang, score := range run.Scores {
+		values[lang] = score
+	}
+	return values
+}
+
+// Cantidad por lenguaje o por tag según el tipo de corrida
+func (run *Run) Values() map[string]float64 {
+	values := make(map[string]float64)
+	if run.Kind == KindTags {
+		for tag, num := range run.Tags {
+			values[tag] = float64(num)
+		}
+		return values
+	}
+	for lang, num := range run.Languages {
+		values[lang] = float64(num)
+	}
+	return values
+}
+
+// Busca una corrida por "latest", "previous", ID o fecha (la última corrida hasta ese día o momento)
+func (store *HistoryStore) Resolve(kind, ref string) (*Run, error) {
+	runs, err := store.Query(Query{Kind: kind})
+	if err != nil {
+		return nil, err
+	}
+	switch ref {
+	case "latest":
+		if len(runs) > 0 {
+			return &runs[len(runs)-1], nil
+		}
+		return nil, common.NewNotFoundError("ninguna corrida de tipo " + kind)
+	case "previous":
+		if len(runs) > 1 {
+			return &runs[len(runs)-2], nil
+		}
+		return nil, common.NewNotFoundError("una corrida anterior de tipo " + kind)
+	}
+	for i := range runs {
+		if runs[i].ID == ref {
+			return &runs[i], nil
+		}
+	}
+
+	var until time.Time
+	if day, err := time.ParseInLocation("2006-01-02", ref, time.Local); err == nil {
+		until = day.AddDate(0, 0, 1)
+	} else if moment, err := time.Parse(time.RFC3339, ref); err == nil {
+		until = moment.Add(time.Nanosecond)
+	} else {
+		return nil, common.NewNotFoundError("la corrida " + ref)
+	}
+	for i := len(runs) - 1; i >= 0; i-- {
+		if runs[i].Timestamp.Before(until) {
+			return &runs[i], nil
+		}
+	}
+	return nil, common.NewNotFoundError("una corrida de tipo " + kind + " hasta " + ref)
+}
@@ -0,0 +1,11 @@
+package main
+
+import (
+	"os"
+	"webscraping/cli"
+)
+
+// Alias de "webscraping compare"
+func main() {
+	os.Exit(cli.Main(append([]string{"compare"}, os.Args[1:]...)))
+}
@@ -0,0 +1,11 @@
+package main
+
+import (
+	"os"
+	"webscraping/cli"
+)
+
+// Alias de "webscraping daemon"
+func main() {
+	os.Exit(cli.Main(append([]string{"daemon"}, os.Args[1:]...)))
+}
@@ -0,0 +1,11 @@
+package main
+
+import (
+	"os"
+	"webscraping/cli"
+)
+
+// Alias de "webscraping history-chart"
+func main() {
+	os.Exit(cli.Main(append([]string{"history-chart"}, os.Args[1:]...)))
+}
@@ -0,0 +1,11 @@
+package main
+
+import (
+	"os"
+	"webscraping/cli"
+)
+
+// Alias de "webscraping serve"
+func main() {
+	os.Exit(cli.Main(append([]string{"serve"}, os.Args[1:]...)))
+}
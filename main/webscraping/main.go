@@ -0,0 +1,10 @@
+package main
+
+import (
+	"os"
+	"webscraping/cli"
+)
+
+func main() {
+	os.Exit(cli.Main(os.Args[1:]))
+}
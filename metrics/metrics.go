@@ -0,0 +1,282 @@
+package metrics
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Métricas publicadas en formato de texto de Prometheus
+const (
+	RequestsTotal      = "webscraping_http_requests_total"
+	RequestDuration    = "webscraping_http_request_duration_seconds"
+	RetriesTotal       = "webscraping_http_retries_total"
+	ParseFailuresTotal = "webscraping_parse_failures_total"
+	RunsTotal          = "webscraping_runs_total"
+	LastSuccess        = "webscraping_last_success_timestamp_seconds"
+	LanguageTopics     = "webscraping_language_topics"
+	TagCount           = "webscraping_tag_count"
+)
+
+const (
+	typeCounter   = "counter"
+	typeGauge     = "gauge"
+	typeHistogram = "histogram"
+)
+
+// Límites superiores de los buckets de latencia, en segundos
+var durationBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+type Config struct {
+	Enabled bool `json:"enabled" yaml:"enabled"`
+	// Donde el daemon publica /metrics, vacío para no publicarlas. El servidor las publica en su propia dirección.
+	Address string `json:"address" yaml:"address"`
+	// Cantidad de tags con mayor cantidad publicados como gauge
+	TopTags int `json:"top_tags" yaml:"top_tags"`
+}
+
+type Labels map[string]string
+
+type series struct {
+	labels Labels
+	value  float64
+	// Solo histogramas: cantidad por bucket (no acumulada), suma y cantidad total
+	buckets []uint64
+	sum     float64
+	count   uint64
+}
+
+type family struct {
+	name, kind, help string
+	buckets          []float64
+	series           map[string]*series
+}
+
+// Registro de métricas en memoria. Un registro nil no registra nada, así los scrapers funcionan sin métricas.
+type Registry struct {
+	mutex    sync.Mutex
+	families map[string]*family
+}
+
+func GetDefaultConfig() Config {
+	return Config{
+		Enabled: true,
+		Address: ":9100",
+		TopTags: 20,
+	}
+}
+
+func NewRegistry() *Registry {
+	var registry Registry
+	registry.families = make(map[string]*family)
+	registry.describe(RequestsTotal, typeCounter, "Consultas HTTP por host y código de estado (error si no hubo respuesta).", nil)
+	registry.describe(RequestDuration, typeHistogram, "Latencia de cada consulta HTTP por host.", durationBuckets)
+	registry.describe(RetriesTotal, typeCounter, "Reintentos de consultas HTTP por host.", nil)
+	registry.describe(ParseFailuresTotal, typeCounter, "Páginas o elementos que no se pudieron leer, por origen.", nil)
+	registry.describe(RunsTotal, typeCounter, "Corridas por ejercicio y resultado.", nil)
+	registry.describe(LastSuccess, typeGauge, "Fecha unix de la última corrida exitosa por ejercicio.", nil)
+	registry.describe(LanguageTopics, typeGauge, "Cantidad de repositorios por lenguaje en la última corrida.", nil)
+	registry.describe(TagCount, typeGauge, "Menciones de los tags con mayor cantidad en la última corrida.", nil)
+	return &registry
+}
+
+func (registry *Registry) describe(name, kind, help string, buckets []float64) {
+	registry.families[name] = &family{name: name, kind: kind, help: help, buckets: buckets, series: make(map[string]*series)}
+}
+
+// Clave de la serie: las etiquetas ordenadas por nombre
+func (labels Labels) String() string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = name + "=\"" + escape(labels[name]) + "\""
+	}
+	return strings.Join(pairs, ",")
+}
+
+func escape(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+func (registry *Registry) get(name string, labels Labels) *series {
+	f := registry.families[name]
+	if f == nil {
+		return nil
+	}
+	key := labels.String()
+	s := f.series[key]
+	if s == nil {
+		s = &series{labels: labels}
+		if f.kind == typeHistogram {
+			s.buckets = make([]uint64, len(f.buckets))
+		}
+		f.series[key] = s
+	}
+	return s
+}
+
+// Suma delta a un contador
+func (registry *Registry) Add(name string, labels Labels, delta float64) {
+	if registry == nil {
+		return
+	}
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	if s := registry.get(name, labels); s != nil {
+		s.value += delta
+	}
+}
+
+// Fija el valor de un gauge
+func (registry *Registry) Set(name string, labels Labels, value float64) {
+	if registry == nil {
+		return
+	}
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	if s := registry.get(name, labels); s != nil {
+		s.value = value
+	}
+}
+
+// Reemplaza todas las series de un gauge, para que no queden valores de corridas anteriores
+func (registry *Registry) Replace(name, label string, values map[string]float64) {
+	if registry == nil {
+		return
+	}
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	f := registry.families[name]
+	if f == nil {
+		return
+	}
+	f.series = make(map[string]*series)
+	for key, value := range values {
+		registry.get(name, Labels{label: key}).value = value
+	}
+}
+
+// Agrega una observación a un histograma
+func (registry *Registry) Observe(name string, labels Labels, value float64) {
+	if registry == nil {
+		return
+	}
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	f := registry.families[name]
+	if f == nil || f.kind != typeHistogram {
+		return
+	}
+	s := registry.get(name, labels)
+	for i, bound := range f.buckets {
+		if value <= bound {
+			s.buckets[i]++
+			break
+		}
+	}
+	s.sum += value
+	s.count++
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// Nombre de la serie con sus etiquetas y una etiqueta extra opcional (le de los histogramas)
+func sample(name string, labels Labels, extra ...string) string {
+	pairs := labels.String()
+	if len(extra) == 2 {
+		if pairs != "" {
+			pairs += ","
+		}
+		pairs += extra[0] + "=\"" + extra[1] + "\""
+	}
+	if pairs == "" {
+		return name
+	}
+	return name + "{" + pairs + "}"
+}
+
+// Escribe todas las métricas en formato de texto de Prometheus, ordenadas por nombre y etiquetas
+func (registry *Registry) Write(w io.Writer) error {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	names := make([]string, 0, len(registry.families))
+	for name := range registry.families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	out := bufio.NewWriter(w)
+	for _, name := range names {
+		f := registry.families[name]
+		out.WriteString("# HELP " + name + " " + f.help + "\n")
+		out.WriteString("# TYPE " + name + " " + f.kind + "\n")
+		keys := make([]string, 0, len(f.series))
+		for key := range f.series {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			s := f.series[key]
+			if f.kind != typeHistogram {
+				out.WriteString(sample(name, s.labels) + " " + formatFloat(s.value) + "\n")
+				continue
+			}
+			var cumulative uint64
+			for i, bound := range f.buckets {
+				cumulative += s.buckets[i]
+				out.WriteString(sample(name+"_bucket", s.labels, "le", formatFloat(bound)) + " " + strconv.FormatUint(cumulative, 10) + "\n")
+			}
+			out.WriteString(sample(name+"_bucket", s.labels, "le", "+Inf") + " " + strconv.FormatUint(s.count, 10) + "\n")
+			out.WriteString(sample(name+"_sum", s.labels) + " " + formatFloat(s.sum) + "\n")
+			out.WriteString(sample(name+"_count", s.labels) + " " + strconv.FormatUint(s.count, 10) + "\n")
+		}
+	}
+	return out.Flush()
+}
+
+// Handler para /metrics
+func (registry *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		registry.Write(w)
+	})
+}
+
+// Publica solo /metrics en address hasta que se cancele el contexto, para el daemon que no tiene servidor propio
+func (registry *Registry) Serve(ctx context.Context, address string, logger zerolog.Logger) error {
+	l := logger.With().Str("struct", "Registry").Str("method", "Serve").Logger()
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", registry.Handler())
+	httpserver := http.Server{Addr: address, Handler: mux}
+	done := make(chan error, 1)
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		done <- httpserver.Shutdown(shutdown)
+	}()
+
+	l.Info().Str("address", address).Msg("Publicando métricas")
+	err := httpserver.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		return <-done
+	}
+	return err
+}
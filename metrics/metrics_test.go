@@ -0,0 +1,144 @@
+package metrics
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func testRegistry() *Registry {
+	registry := &Registry{families: make(map[string]*family)}
+	registry.describe("test_requests_total", typeCounter, "Consultas de prueba.", nil)
+	registry.describe("test_duration_seconds", typeHistogram, "Latencia de prueba.", []float64{0.1, 1, 10})
+	registry.describe("test_value", typeGauge, "Valor de prueba.", nil)
+	return registry
+}
+
+func write(t *testing.T, registry *Registry) string {
+	var sb strings.Builder
+	if err := registry.Write(&sb); err != nil {
+		t.Fatal(err)
+	}
+	return sb.String()
+}
+
+func TestWrite(t *testing.T) {
+	registry := testRegistry()
+	registry.Add("test_requests_total", Labels{"status": "200", "host": "github.com"}, 1)
+	registry.Add("test_requests_total", Labels{"status": "200", "host": "github.com"}, 2)
+	registry.Add("test_requests_total", Labels{"status": "error", "host": "tiobe.com"}, 1)
+	registry.Set("test_value", nil, 1.5)
+	registry.Observe("test_duration_seconds", Labels{"host": "github.com"}, 0.05)
+	registry.Observe("test_duration_seconds", Labels{"host": "github.com"}, 2)
+
+	want := strings.Join([]string{
+		"# HELP test_duration_seconds Latencia de prueba.",
+		"# TYPE test_duration_seconds histogram",
+		`test_duration_seconds_bucket{host="github.com",le="0.1"} 1`,
+		`test_duration_seconds_bucket{host="github.com",le="1"} 1`,
+		`test_duration_seconds_bucket{host="github.com",le="10"} 2`,
+		`test_duration_seconds_bucket{host="github.com",le="+Inf"} 2`,
+		`test_duration_seconds_sum{host="github.com"} 2.05`,
+		`test_duration_seconds_count{host="github.com"} 2`,
+		"# HELP test_requests_total Consultas de prueba.",
+		"# TYPE test_requests_total counter",
+		`test_requests_total{host="github.com",status="200"} 3`,
+		`test_requests_total{host="tiobe.com",status="error"} 1`,
+		"# HELP test_value Valor de prueba.",
+		"# TYPE test_value gauge",
+		"test_value 1.5",
+		"",
+	}, "\n")
+	if got := write(t, registry); got != want {
+		t.Errorf("got:\n%v\nwant:\n%v", got, want)
+	}
+}
+
+func TestWriteEscapesLabels(t *testing.T) {
+	registry := testRegistry()
+	registry.Set("test_value", Labels{"tag": "a\"b\\c\nd"}, 1)
+	want := `test_value{tag="a\"b\\c\nd"} 1`
+	if got := write(t, registry); !strings.Contains(got, want+"\n") {
+		t.Errorf("missing %v in:\n%v", want, got)
+	}
+}
+
+func TestObserveBuckets(t *testing.T) {
+	registry := testRegistry()
+	// En el límite cuenta para el bucket, sobre el último solo para +Inf
+	for _, value := range []float64{0, 0.1, 0.5, 1, 1.01, 10, 11, 100} {
+		registry.Observe("test_duration_seconds", nil, value)
+	}
+	s := registry.families["test_duration_seconds"].series[""]
+	want := []uint64{2, 2, 2}
+	for i := range want {
+		if s.buckets[i] != want[i] {
+			t.Errorf("buckets = %v, want %v", s.buckets, want)
+			break
+		}
+	}
+	if s.count != 8 || math.Abs(s.sum-123.61) > 1e-9 {
+		t.Errorf("count = %v, sum = %v, want 8 and 123.61", s.count, s.sum)
+	}
+	got := write(t, registry)
+	for _, line := range []string{
+		`test_duration_seconds_bucket{le="0.1"} 2`,
+		`test_duration_seconds_bucket{le="1"} 4`,
+		`test_duration_seconds_bucket{le="10"} 6`,
+		`test_duration_seconds_bucket{le="+Inf"} 8`,
+		`test_duration_seconds_count 8`,
+	} {
+		if !strings.Contains(got, line+"\n") {
+			t.Errorf("missing %v in:\n%v", line, got)
+		}
+	}
+}
+
+func TestObserveOnlyHistograms(t *testing.T) {
+	registry := testRegistry()
+	registry.Observe("test_value", nil, 1)
+	registry.Observe("unknown", nil, 1)
+	registry.Add("unknown", nil, 1)
+	if got := write(t, registry); strings.Contains(got, "\ntest_value") || strings.Contains(got, "unknown") {
+		t.Errorf("unexpected series in:\n%v", got)
+	}
+}
+
+func TestReplace(t *testing.T) {
+	registry := testRegistry()
+	registry.Replace("test_value", "tag", map[string]float64{"go": 3, "cli": 1})
+	registry.Replace("test_value", "tag", map[string]float64{"go": 4})
+	got := write(t, registry)
+	if !strings.Contains(got, `test_value{tag="go"} 4`) || strings.Contains(got, "cli") {
+		t.Errorf("got:\n%v", got)
+	}
+}
+
+func TestNilRegistry(t *testing.T) {
+	var registry *Registry
+	registry.Add(RequestsTotal, nil, 1)
+	registry.Set(LastSuccess, nil, 1)
+	registry.Observe(RequestDuration, nil, 1)
+	registry.Replace(TagCount, "tag", nil)
+}
+
+func TestHandler(t *testing.T) {
+	registry := NewRegistry()
+	registry.Add(RunsTotal, Labels{"kind": "languages", "result": "ok"}, 1)
+	w := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if !strings.HasPrefix(w.Header().Get("Content-Type"), "text/plain; version=0.0.4") {
+		t.Errorf("content type = %v", w.Header().Get("Content-Type"))
+	}
+	body := w.Body.String()
+	for _, name := range []string{RequestsTotal, RequestDuration, RetriesTotal, ParseFailuresTotal, RunsTotal, LastSuccess, LanguageTopics, TagCount} {
+		if !strings.Contains(body, "# TYPE "+name+" ") {
+			t.Errorf("missing TYPE for %v", name)
+		}
+	}
+	if !strings.Contains(body, `webscraping_runs_total{kind="languages",result="ok"} 1`+"\n") {
+		t.Errorf("missing run sample in:\n%v", body)
+	}
+}
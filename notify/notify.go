@@ -0,0 +1,320 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+	"webscraping/common"
+
+	"github.com/rs/zerolog"
+)
+
+// Tipos de evento
+const (
+	EventEnteredTop     = "entered_top"
+	EventLeftTop        = "left_top"
+	EventRankChange     = "rank_change"
+	EventNewTopTag      = "new_top_tag"
+	EventScrapeFailed   = "scrape_failed"
+	EventLowSuccessRate = "low_success_rate"
+)
+
+// Formatos del cuerpo enviado a cada destino
+const (
+	FormatJSON  = "json"
+	FormatSlack = "slack"
+)
+
+// Mismos valores que history.KindLanguages y history.KindTags
+const (
+	kindLanguages = "languages"
+	kindTags      = "tags"
+)
+
+type Target struct {
+	URL string `json:"url" yaml:"url"`
+	// json (por defecto) o slack
+	Format string `json:"format" yaml:"format"`
+}
+
+type Config struct {
+	Enabled bool     `json:"enabled" yaml:"enabled"`
+	Targets []Target `json:"targets" yaml:"targets"`
+	// Se avisa cuando un lenguaje entra o sale de los primeros top_languages
+	TopLanguages int `json:"top_languages" yaml:"top_languages"`
+	// Se avisa cuando un lenguaje cambia más de rank_change puestos, 0 para no avisar
+	RankChange int `json:"rank_change" yaml:"rank_change"`
+	// Se avisa cuando un tag entra a los primeros top_tags
+	TopTags int `json:"top_tags" yaml:"top_tags"`
+	// Se avisa si la fracción de consultas exitosas es menor, 0 para no avisar
+	MinSuccessRate float64 `json:"min_success_rate" yaml:"min_success_rate"`
+	RetryDelaysMs  []int   `json:"retry_delays_ms" yaml:"retry_delays_ms"`
+	TimeoutSeconds int     `json:"timeout_seconds" yaml:"timeout_seconds"`
+}
+
+type Event struct {
+	Type    string `json:"type"`
+	Kind    string `json:"kind"`
+	Name    string `json:"name,omitempty"`
+	OldRank int    `json:"old_rank,omitempty"`
+	NewRank int    `json:"new_rank,omitempty"`
+	Message string `json:"message"`
+}
+
+// Cuerpo enviado con el formato json
+type Payload struct {
+	Kind      string    `json:"kind"`
+	RunID     string    `json:"run_id,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+	Events    []Event   `json:"events"`
+}
+
+type Notifier struct {
+	config Config
+	client *http.Client
+	logger zerolog.Logger
+}
+
+func GetDefaultConfig() Config {
+	return Config{
+		Enabled:        false,
+		Targets:        []Target{},
+		TopLanguages:   10,
+		RankChange:     3,
+		TopTags:        20,
+		MinSuccessRate: 0.8,
+		RetryDelaysMs:  []int{500, 1000, 2000},
+		TimeoutSeconds: 10,
+	}
+}
+
+// Revisa que cada destino tenga url y un formato soportado
+func (config *Config) Validate() error {
+	for _, target := range config.Targets {
+		if target.URL == "" {
+			return common.NewConfigError("notifications.targets.url", target.URL)
+		}
+		switch strings.ToLower(target.Format) {
+		case "", FormatJSON, FormatSlack:
+		default:
+			return common.NewConfigError("notifications.targets.format", target.Format)
+		}
+	}
+	return nil
+}
+
+func NewNotifier(config Config, logger zerolog.Logger) *Notifier {
+	var notifier Notifier
+	notifier.config = config
+	notifier.client = &http.Client{Timeout: time.Duration(config.TimeoutSeconds) * time.Second}
+	notifier.logger = logger.With().Str("struct", "Notifier").Logger()
+	return &notifier
+}
+
+// Puesto de cada nombre, de mayor a menor valor (los empates por nombre)
+func ranks(values map[string]float64) map[string]int {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if values[names[i]] != values[names[j]] {
+			return values[names[i]] > values[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	ret := make(map[string]int)
+	for i, name := range names {
+		ret[name] = i + 1
+	}
+	return ret
+}
+
+func inTop(rank, top int) bool {
+	return rank > 0 && rank <= top
+}
+
+// Eventos de puesto entre la corrida anterior y la actual. Los valores faltantes no tienen puesto.
+// Los nombres de missing no se pudieron leer en alguna de las dos corridas (consulta fallida o valor
+// anómalo descartado): no generan eventos y se ordenan con el valor de la otra corrida, así su ausencia
+// no mueve los puestos de los demás.
+func (notifier *Notifier) Compare(kind string, previous, current map[string]float64, missing []string) []Event {
+	skip := make(map[string]bool)
+	before := make(map[string]float64, len(previous))
+	for name, value := range previous {
+		before[name] = value
+	}
+	after := make(map[string]float64, len(current))
+	for name, value := range current {
+		after[name] = value
+	}
+	for _, name := range missing {
+		skip[name] = true
+		if value, ok := current[name]; ok {
+			if _, ok := before[name]; !ok {
+				before[name] = value
+			}
+		}
+		if value, ok := previous[name]; ok {
+			if _, ok := after[name]; !ok {
+				after[name] = value
+			}
+		}
+	}
+
+	oldranks, newranks := ranks(before), ranks(after)
+	names := make([]string, 0, len(oldranks)+len(newranks))
+	for name := range oldranks {
+		names = append(names, name)
+	}
+	for name := range newranks {
+		if _, ok := oldranks[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var events []Event
+	for _, name := range names {
+		if skip[name] {
+			continue
+		}
+		oldrank, newrank := oldranks[name], newranks[name]
+		event := Event{Kind: kind, Name: name, OldRank: oldrank, NewRank: newrank}
+		switch kind {
+		case kindLanguages:
+			top := notifier.config.TopLanguages
+			if inTop(newrank, top) && !inTop(oldrank, top) {
+				event.Type = EventEnteredTop
+				event.Message = fmt.Sprintf("%v entró al top %d (ahora %v, antes %v)", name, top, rankString(newrank), rankString(oldrank))
+				events = append(events, event)
+			} else if inTop(oldrank, top) && !inTop(newrank, top) {
+				event.Type = EventLeftTop
+				event.Message = fmt.Sprintf("%v salió del top %d (ahora %v, antes %v)", name, top, rankString(newrank), rankString(oldrank))
+				events = append(events, event)
+			}
+			if change := newrank - oldrank; oldrank > 0 && newrank > 0 && notifier.config.RankChange > 0 && (change > notifier.config.RankChange || -change > notifier.config.RankChange) {
+				event.Type = EventRankChange
+				event.Message = fmt.Sprintf("%v cambió %+d puestos (%d -> %d)", name, -change, oldrank, newrank)
+				events = append(events, event)
+			}
+		case kindTags:
+			top := notifier.config.TopTags
+			if inTop(newrank, top) && !inTop(oldrank, top) {
+				event.Type = EventNewTopTag
+				event.Message = fmt.Sprintf("Nuevo tag en el top %d: %v (ahora %v, antes %v)", top, name, rankString(newrank), rankString(oldrank))
+				events = append(events, event)
+			}
+		}
+	}
+	return events
+}
+
+func rankString(rank int) string {
+	if rank == 0 {
+		return "sin puesto"
+	}
+	return fmt.Sprint(rank)
+}
+
+// Evento si la fracción de consultas exitosas es menor a min_success_rate
+func (notifier *Notifier) CheckSuccess(kind string, requests, failures int) []Event {
+	if requests == 0 || notifier.config.MinSuccessRate <= 0 {
+		return nil
+	}
+	rate := float64(requests-failures) / float64(requests)
+	if rate >= notifier.config.MinSuccessRate {
+		return nil
+	}
+	return []Event{{
+		Type:    EventLowSuccessRate,
+		Kind:    kind,
+		Message: fmt.Sprintf("Solo %.0f%% de las consultas de %v fueron exitosas (%d de %d, mínimo %.0f%%)", rate*100, kind, requests-failures, requests, notifier.config.MinSuccessRate*100),
+	}}
+}
+
+// Evento de una corrida que terminó con error
+func (notifier *Notifier) Failure(kind string, err error) Event {
+	return Event{
+		Type:    EventScrapeFailed,
+		Kind:    kind,
+		Message: fmt.Sprintf("Falló la corrida de %v: %v", kind, err),
+	}
+}
+
+// Mensaje compatible con los webhooks entrantes de Slack
+func slackMessage(payload *Payload) interface{} {
+	title := "*webscraping " + payload.Kind + "*"
+	if payload.RunID != "" {
+		title += " (" + payload.RunID + ")"
+	}
+	lines := []string{title}
+	for _, event := range payload.Events {
+		lines = append(lines, "• "+event.Message)
+	}
+	return map[string]string{"text": strings.Join(lines, "\n")}
+}
+
+// Envía los eventos a todos los destinos. Retorna el último error, pero siempre intenta todos los destinos.
+func (notifier *Notifier) Send(payload Payload) error {
+	l := notifier.logger.With().Str("method", "Send").Logger()
+
+	if len(payload.Events) == 0 {
+		l.Trace().Msg("Sin eventos para notificar")
+		return nil
+	}
+	var lastError error
+	for _, target := range notifier.config.Targets {
+		var body interface{} = &payload
+		switch strings.ToLower(target.Format) {
+		case "", FormatJSON:
+		case FormatSlack:
+			body = slackMessage(&payload)
+		default:
+			l.Error().Str("format", target.Format).Msg("Formato de notificación no soportado! Use json o slack")
+			lastError = common.NewConfigError("notifications.targets.format", target.Format)
+			continue
+		}
+		content, err := json.Marshal(body)
+		if err != nil {
+			lastError = err
+			continue
+		}
+		if err = notifier.post(target.URL, content); err != nil {
+			l.Error().Err(err).Str("url", target.URL).Msg("No se pudo enviar la notificación")
+			lastError = err
+			continue
+		}
+		l.Info().Str("url", target.URL).Int("eventos", len(payload.Events)).Msg("Notificación enviada")
+	}
+	return lastError
+}
+
+// POST reintentando con retry_delays_ms mientras falle la conexión o el destino no retorne 2xx
+func (notifier *Notifier) post(url string, content []byte) error {
+	l := notifier.logger.With().Str("method", "post").Str("url", url).Logger()
+
+	var err error
+	delays := append([]int{0}, notifier.config.RetryDelaysMs...)
+	for i, delay := range delays {
+		if i > 0 {
+			l.Warn().Err(err).Int("Tiempo espera", delay).Msg("El destino retornó un error. Reintentando después de tiempo espera...")
+			time.Sleep(time.Millisecond * time.Duration(delay))
+		}
+		var response *http.Response
+		response, err = notifier.client.Post(url, "application/json", bytes.NewReader(content))
+		if err != nil {
+			continue
+		}
+		response.Body.Close()
+		if response.StatusCode >= 200 && response.StatusCode < 300 {
+			return nil
+		}
+		err = common.NewStatusCodeError(response.StatusCode)
+	}
+	return err
+}
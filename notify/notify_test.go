@@ -0,0 +1,281 @@
+package notify
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"webscraping/common"
+
+	"github.com/rs/zerolog"
+)
+
+func testNotifier(config Config) *Notifier {
+	return NewNotifier(config, zerolog.Nop())
+}
+
+func events(list []Event) string {
+	var s string
+	for _, event := range list {
+		s += fmt.Sprintf("%v %v %d->%d; ", event.Type, event.Name, event.OldRank, event.NewRank)
+	}
+	return s
+}
+
+func TestCompareLanguages(t *testing.T) {
+	config := GetDefaultConfig()
+	config.TopLanguages = 2
+	config.RankChange = 1
+	notifier := testNotifier(config)
+
+	previous := map[string]float64{"python": 400, "go": 300, "c": 200, "rust": 100}
+	current := map[string]float64{"python": 400, "rust": 350, "c": 200, "go": 100}
+	got := events(notifier.Compare(kindLanguages, previous, current, nil))
+	want := "left_top go 2->4; rank_change go 2->4; entered_top rust 4->2; rank_change rust 4->2; "
+	if got != want {
+		t.Errorf("got %v\nwant %v", got, want)
+	}
+}
+
+func TestCompareMissing(t *testing.T) {
+	config := GetDefaultConfig()
+	config.TopLanguages = 2
+	config.RankChange = 1
+	notifier := testNotifier(config)
+
+	previous := map[string]float64{"python": 400, "go": 300, "c": 200, "rust": 100}
+	// python no se pudo leer: sin missing sale del top y todos suben un puesto
+	current := map[string]float64{"go": 300, "c": 200, "rust": 100}
+	if got := notifier.Compare(kindLanguages, previous, current, nil); len(got) == 0 {
+		t.Fatal("expected false events without missing")
+	}
+	if got := notifier.Compare(kindLanguages, previous, current, []string{"python"}); len(got) != 0 {
+		t.Errorf("got %v, want no events for a language that could not be read", events(got))
+	}
+
+	// En la corrida siguiente python vuelve: faltaba en la anterior, no es un ingreso al top
+	previous, current = current, map[string]float64{"python": 400, "go": 300, "c": 200, "rust": 100}
+	if got := notifier.Compare(kindLanguages, previous, current, []string{"python"}); len(got) != 0 {
+		t.Errorf("got %v, want no events when the language returns", events(got))
+	}
+}
+
+func TestCompareTags(t *testing.T) {
+	config := GetDefaultConfig()
+	config.TopTags = 2
+	notifier := testNotifier(config)
+
+	previous := map[string]float64{"go": 10, "cli": 8, "api": 5}
+	current := map[string]float64{"go": 12, "api": 9, "cli": 8, "wasm": 20}
+	got := events(notifier.Compare(kindTags, previous, current, nil))
+	want := "new_top_tag wasm 0->1; "
+	if got != want {
+		t.Errorf("got %v\nwant %v", got, want)
+	}
+	// wasm descartado como anómalo no desplaza a los demás
+	current = map[string]float64{"go": 12, "cli": 9, "api": 8}
+	if got := notifier.Compare(kindTags, previous, current, []string{"wasm"}); len(got) != 0 {
+		t.Errorf("got %v, want no events", events(got))
+	}
+}
+
+func TestCheckSuccess(t *testing.T) {
+	notifier := testNotifier(GetDefaultConfig())
+	if got := notifier.CheckSuccess(kindLanguages, 10, 2); len(got) != 0 {
+		t.Errorf("80%% success should not notify: %v", got)
+	}
+	got := notifier.CheckSuccess(kindLanguages, 10, 3)
+	if len(got) != 1 || got[0].Type != EventLowSuccessRate {
+		t.Errorf("got %v, want low_success_rate", got)
+	}
+	if got := notifier.CheckSuccess(kindLanguages, 0, 0); len(got) != 0 {
+		t.Errorf("no requests should not notify: %v", got)
+	}
+}
+
+// Receptor local que responde con statuses en orden (el último se repite) y guarda los cuerpos recibidos
+type receiver struct {
+	mutex    sync.Mutex
+	statuses []int
+	bodies   [][]byte
+	server   *httptest.Server
+}
+
+func newReceiver(t *testing.T, statuses ...int) *receiver {
+	rec := &receiver{statuses: statuses}
+	rec.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("got %v with content type %q, want a json POST", r.Method, r.Header.Get("Content-Type"))
+		}
+		body, _ := io.ReadAll(r.Body)
+		rec.mutex.Lock()
+		status := rec.statuses[len(rec.statuses)-1]
+		if len(rec.bodies) < len(rec.statuses) {
+			status = rec.statuses[len(rec.bodies)]
+		}
+		rec.bodies = append(rec.bodies, body)
+		rec.mutex.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(rec.server.Close)
+	return rec
+}
+
+func (rec *receiver) requests() [][]byte {
+	rec.mutex.Lock()
+	defer rec.mutex.Unlock()
+	return rec.bodies
+}
+
+func sendConfig(format string, delays []int, urls ...string) Config {
+	config := GetDefaultConfig()
+	config.Enabled = true
+	config.RetryDelaysMs = delays
+	config.TimeoutSeconds = 5
+	for _, url := range urls {
+		config.Targets = append(config.Targets, Target{URL: url, Format: format})
+	}
+	return config
+}
+
+func testPayload() Payload {
+	return Payload{
+		Kind:      kindLanguages,
+		RunID:     "20240101T060000.000-languages",
+		Timestamp: time.Date(2024, 1, 1, 6, 0, 0, 0, time.UTC),
+		Events: []Event{
+			{Type: EventEnteredTop, Kind: kindLanguages, Name: "rust", OldRank: 11, NewRank: 9, Message: "rust entró al top 10 (ahora 9, antes 11)"},
+			{Type: EventLowSuccessRate, Kind: kindLanguages, Message: "Solo 50% de las consultas de languages fueron exitosas"},
+		},
+	}
+}
+
+func TestSendJSON(t *testing.T) {
+	rec := newReceiver(t, http.StatusOK)
+	notifier := testNotifier(sendConfig(FormatJSON, nil, rec.server.URL))
+	if err := notifier.Send(testPayload()); err != nil {
+		t.Fatal(err)
+	}
+	requests := rec.requests()
+	if len(requests) != 1 {
+		t.Fatalf("got %v requests, want 1", len(requests))
+	}
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(requests[0], &body); err != nil {
+		t.Fatal(err)
+	}
+	if body["kind"] != "languages" || body["run_id"] != "20240101T060000.000-languages" || body["timestamp"] != "2024-01-01T06:00:00Z" {
+		t.Errorf("unexpected payload header: %v", body)
+	}
+	list, ok := body["events"].([]interface{})
+	if !ok || len(list) != 2 {
+		t.Fatalf("got events %v, want 2", body["events"])
+	}
+	first := list[0].(map[string]interface{})
+	want := map[string]interface{}{"type": "entered_top", "kind": "languages", "name": "rust", "old_rank": 11.0, "new_rank": 9.0, "message": "rust entró al top 10 (ahora 9, antes 11)"}
+	if fmt.Sprint(first) != fmt.Sprint(want) {
+		t.Errorf("got event %v\nwant %v", first, want)
+	}
+	// Los campos vacíos se omiten
+	second := list[1].(map[string]interface{})
+	for _, field := range []string{"name", "old_rank", "new_rank"} {
+		if _, ok := second[field]; ok {
+			t.Errorf("field %v should be omitted: %v", field, second)
+		}
+	}
+}
+
+func TestSendSlack(t *testing.T) {
+	rec := newReceiver(t, http.StatusOK)
+	notifier := testNotifier(sendConfig("Slack", nil, rec.server.URL))
+	if err := notifier.Send(testPayload()); err != nil {
+		t.Fatal(err)
+	}
+	var body map[string]string
+	if err := json.Unmarshal(rec.requests()[0], &body); err != nil {
+		t.Fatal(err)
+	}
+	if len(body) != 1 {
+		t.Errorf("got %v, want only the text field", body)
+	}
+	want := strings.Join([]string{
+		"*webscraping languages* (20240101T060000.000-languages)",
+		"• rust entró al top 10 (ahora 9, antes 11)",
+		"• Solo 50% de las consultas de languages fueron exitosas",
+	}, "\n")
+	if body["text"] != want {
+		t.Errorf("got text %q\nwant %q", body["text"], want)
+	}
+}
+
+func TestSendRetries(t *testing.T) {
+	rec := newReceiver(t, http.StatusInternalServerError, http.StatusServiceUnavailable, http.StatusOK)
+	notifier := testNotifier(sendConfig(FormatJSON, []int{1, 1, 1}, rec.server.URL))
+	if err := notifier.Send(testPayload()); err != nil {
+		t.Fatal(err)
+	}
+	requests := rec.requests()
+	if len(requests) != 3 {
+		t.Fatalf("got %v requests, want 2 failures and a success", len(requests))
+	}
+	for _, body := range requests[1:] {
+		if string(body) != string(requests[0]) {
+			t.Errorf("retries should send the same body: %s", body)
+		}
+	}
+}
+
+func TestSendGivesUp(t *testing.T) {
+	rec := newReceiver(t, http.StatusBadGateway)
+	notifier := testNotifier(sendConfig(FormatJSON, []int{1, 2}, rec.server.URL))
+	err := notifier.Send(testPayload())
+	var status *common.StatusCodeError
+	if !errors.As(err, &status) || err.Error() != common.NewStatusCodeError(http.StatusBadGateway).Error() {
+		t.Errorf("got %v, want status code error 502", err)
+	}
+	if got := len(rec.requests()); got != 3 {
+		t.Errorf("got %v requests, want the first attempt and one per retry delay", got)
+	}
+}
+
+func TestSendNon2xx(t *testing.T) {
+	rec := newReceiver(t, http.StatusNotFound)
+	ok := newReceiver(t, http.StatusNoContent)
+	notifier := testNotifier(sendConfig(FormatJSON, nil, rec.server.URL, ok.server.URL))
+	err := notifier.Send(testPayload())
+	if err == nil || err.Error() != common.NewStatusCodeError(http.StatusNotFound).Error() {
+		t.Errorf("got %v, want status code error 404", err)
+	}
+	// Un destino fallido no impide enviar a los demás
+	if len(ok.requests()) != 1 {
+		t.Errorf("got %v requests to the second target, want 1", len(ok.requests()))
+	}
+}
+
+func TestSendNoEvents(t *testing.T) {
+	rec := newReceiver(t, http.StatusOK)
+	notifier := testNotifier(sendConfig(FormatJSON, nil, rec.server.URL))
+	if err := notifier.Send(Payload{Kind: kindTags}); err != nil {
+		t.Fatal(err)
+	}
+	if len(rec.requests()) != 0 {
+		t.Errorf("nothing should be sent without events")
+	}
+}
+
+func TestSendConnectionError(t *testing.T) {
+	rec := newReceiver(t, http.StatusOK)
+	url := rec.server.URL
+	rec.server.Close()
+	notifier := testNotifier(sendConfig(FormatJSON, []int{1}, url))
+	if err := notifier.Send(testPayload()); err == nil {
+		t.Error("expected error for a closed receiver")
+	}
+}
@@ -0,0 +1,323 @@
+package resultproc
+
+import (
+	"fmt"
+	"os"
+	"webscraping/common"
+	"webscraping/staticchart"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/opts"
+	"github.com/go-echarts/go-echarts/v2/render"
+	"github.com/rs/zerolog"
+)
+
+// Tipos de gráfica soportados
+const (
+	ChartBar           = "bar"
+	ChartHorizontalBar = "hbar"
+	ChartPie           = "pie"
+	ChartTreemap       = "treemap"
+	ChartFunnel        = "funnel"
+)
+
+// Valores a graficar
+const (
+	ChartCounts = "counts"
+	ChartScores = "scores"
+)
+
+type ChartConfig struct {
+	Type string `json:"type" yaml:"type"`
+	Top  int    `json:"top" yaml:"top"`
+	// Vacíos para usar el título y subtítulo generados por cada resultado
+	Title      string `json:"title" yaml:"title"`
+	Subtitle   string `json:"subtitle" yaml:"subtitle"`
+	Width      int    `json:"width" yaml:"width"`
+	Height     int    `json:"height" yaml:"height"`
+	Theme      string `json:"theme" yaml:"theme"`
+	XAxisLabel string `json:"x_axis_label" yaml:"x_axis_label"`
+	YAxisLabel string `json:"y_axis_label" yaml:"y_axis_label"`
+	Values     string `json:"values" yaml:"values"`
+}
+
+type ChartsConfig struct {
+	Languages ChartConfig     `json:"languages" yaml:"languages"`
+	Tags      ChartConfig     `json:"tags" yaml:"tags"`
+	Composite ChartConfig     `json:"composite" yaml:"composite"`
+	WordCloud WordCloudConfig `json:"wordcloud" yaml:"wordcloud"`
+}
+
+// Un elemento a graficar, con su cantidad y su puntaje
+type ChartItem struct {
+	Name  string
+	Count float64
+	Score float64
+	// Aporte de cada serie en las barras apiladas, en el orden de las series
+	Parts []float64
+}
+
+// Revisa el tipo de gráfica y los valores a graficar
+func (config *ChartConfig) Validate() error {
+	switch config.Type {
+	case ChartBar, ChartHorizontalBar, ChartPie, ChartTreemap, ChartFunnel:
+	default:
+		return common.NewConfigError("chart.type", config.Type)
+	}
+	if config.Values != ChartCounts && config.Values != ChartScores {
+		return common.NewConfigError("chart.values", config.Values)
+	}
+	return nil
+}
+
+func GetDefaultChartsConfig() ChartsConfig {
+	return ChartsConfig{
+		Languages: ChartConfig{
+			Type:       ChartBar,
+			Top:        10,
+			Width:      1920,
+			Height:     600,
+			Theme:      "white",
+			XAxisLabel: "Lenguaje",
+			YAxisLabel: "Repositorios",
+			Values:     ChartCounts,
+		},
+		Tags: ChartConfig{
+			Type:       ChartBar,
+			Top:        20,
+			Width:      1920,
+			Height:     600,
+			Theme:      "white",
+			XAxisLabel: "Tag",
+			YAxisLabel: "Menciones",
+			Values:     ChartCounts,
+		},
+		Composite: ChartConfig{
+			Type:       ChartBar,
+			Top:        0,
+			Width:      1920,
+			Height:     600,
+			Theme:      "white",
+			XAxisLabel: "Lenguaje",
+			YAxisLabel: "Puntaje",
+			Values:     ChartScores,
+		},
+		WordCloud: GetDefaultWordCloudConfig(),
+	}
+}
+
+// Nombres y valores de los primeros config.Top elementos, según config.Values
+func chartData(config ChartConfig, items []ChartItem) ([]string, []float64, error) {
+	if config.Values != ChartCounts && config.Values != ChartScores {
+		return nil, nil, common.NewConfigError("chart.values", config.Values)
+	}
+	var names []string
+	var values []float64
+	for _, item := range topItems(config, items) {
+		names = append(names, item.Name)
+		if config.Values == ChartScores {
+			values = append(values, item.Score)
+		} else {
+			values = append(values, item.Count)
+		}
+	}
+	return names, values, nil
+}
+
+func topItems(config ChartConfig, items []ChartItem) []ChartItem {
+	if config.Top > 0 && len(items) > config.Top {
+		return items[0:config.Top]
+	}
+	return items
+}
+
+func chartTitles(config ChartConfig, title, subtitle string) (string, string) {
+	if config.Title != "" {
+		title = config.Title
+	}
+	if config.Subtitle != "" {
+		subtitle = config.Subtitle
+	}
+	return title, subtitle
+}
+
+func chartSeriesName(config ChartConfig) string {
+	if config.Values == ChartScores {
+		return "Puntaje"
+	}
+	return "Cantidad"
+}
+
+// Arma la gráfica de los primeros config.Top elementos, en el orden recibido, y la guarda en htmlname.
+// title y subtitle se usan cuando la configuración no los define. Con stack las barras se apilan con una
+// serie por nombre a partir de las Parts de cada elemento; las demás gráficas usan el total.
+func buildChart(htmlname string, config ChartConfig, items []ChartItem, stack []string, title, subtitle string, logger zerolog.Logger) error {
+	l := logger.With().Str("function", "buildChart").Str("type", config.Type).Logger()
+
+	names, values, err := chartData(config, items)
+	if err != nil {
+		l.Error().Str("values", config.Values).Msg("Valores a graficar no soportados! Use counts o scores")
+		return err
+	}
+	title, subtitle = chartTitles(config, title, subtitle)
+
+	l.Trace().Msg("Configurar opciones")
+	global := []charts.GlobalOpts{
+		charts.WithTitleOpts(opts.Title{
+			Title:    title,
+			Subtitle: subtitle,
+		}),
+		charts.WithTooltipOpts(opts.Tooltip{Show: true}),
+		charts.WithInitializationOpts(opts.Initialization{
+			Width:  fmt.Sprintf("%dpx", config.Width),
+			Height: fmt.Sprintf("%dpx", config.Height),
+			Theme:  config.Theme,
+		}),
+	}
+
+	series := chartSeriesName(config)
+
+	var chart render.Renderer
+	switch config.Type {
+	case ChartBar, ChartHorizontalBar:
+		bar := charts.NewBar()
+		// Sin stack una sola serie con los valores
+		seriesNames := []string{series}
+		bars := [][]opts.BarData{make([]opts.BarData, 0, len(values))}
+		for _, value := range values {
+			bars[0] = append(bars[0], opts.BarData{Value: value})
+		}
+		if len(stack) > 0 {
+			seriesNames = stack
+			bars = make([][]opts.BarData, len(stack))
+			for _, item := range topItems(config, items) {
+				for i := range stack {
+					var value float64
+					if i < len(item.Parts) {
+						value = item.Parts[i]
+					}
+					bars[i] = append(bars[i], opts.BarData{Value: value})
+				}
+			}
+			global = append(global, charts.WithLegendOpts(opts.Legend{Show: true, Right: "10%"}))
+		}
+		if config.Type == ChartHorizontalBar {
+			// El mayor queda arriba y el eje de categorías pasa a ser el vertical
+			for i, j := 0, len(names)-1; i < j; i, j = i+1, j-1 {
+				names[i], names[j] = names[j], names[i]
+				for _, data := range bars {
+					data[i], data[j] = data[j], data[i]
+				}
+			}
+			global = append(global,
+				charts.WithXAxisOpts(opts.XAxis{Name: config.YAxisLabel, Type: "value"}),
+				charts.WithYAxisOpts(opts.YAxis{Name: config.XAxisLabel, Type: "category"}))
+			bar.XYReversal()
+		} else {
+			global = append(global,
+				charts.WithXAxisOpts(opts.XAxis{Name: config.XAxisLabel}),
+				charts.WithYAxisOpts(opts.YAxis{Name: config.YAxisLabel}),
+				charts.WithDataZoomOpts(opts.DataZoom{
+					Type:  "slider",
+					Start: 0,
+					End:   100,
+				}))
+		}
+		bar.SetGlobalOptions(global...)
+		bar.SetXAxis(names)
+		var seriesOpts []charts.SeriesOpts
+		if len(stack) > 0 {
+			seriesOpts = append(seriesOpts, charts.WithBarChartOpts(opts.BarChart{Stack: "total"}))
+		}
+		for i, name := range seriesNames {
+			bar.AddSeries(name, bars[i], seriesOpts...)
+		}
+		chart = bar
+	case ChartPie:
+		pie := charts.NewPie()
+		data := make([]opts.PieData, 0, len(values))
+		for i, value := range values {
+			data = append(data, opts.PieData{Name: names[i], Value: value})
+		}
+		pie.SetGlobalOptions(global...)
+		pie.AddSeries(series, data, charts.WithLabelOpts(opts.Label{Show: true, Formatter: "{b}: {d}%"}))
+		chart = pie
+	case ChartTreemap:
+		// go-echarts no tiene treemap, pero el sunburst usa los mismos datos jerárquicos (nombre, valor e
+		// hijos) y echarts dibuja la serie como treemap. Con stack cada componente es un rectángulo hijo.
+		sunburst := charts.NewSunburst()
+		data := make([]opts.SunBurstData, 0, len(values))
+		for i, item := range topItems(config, items) {
+			node := opts.SunBurstData{Name: names[i], Value: values[i]}
+			for j, part := range stack {
+				if j < len(item.Parts) && item.Parts[j] > 0 {
+					node.Children = append(node.Children, &opts.SunBurstData{Name: part, Value: item.Parts[j]})
+				}
+			}
+			data = append(data, node)
+		}
+		sunburst.SetGlobalOptions(global...)
+		sunburst.AddSeries(series, data, charts.WithLabelOpts(opts.Label{Show: true, Formatter: "{b}"}), func(s *charts.SingleSeries) {
+			s.Type = ChartTreemap
+		})
+		chart = sunburst
+	case ChartFunnel:
+		funnel := charts.NewFunnel()
+		data := make([]opts.FunnelData, 0, len(values))
+		for i, value := range values {
+			data = append(data, opts.FunnelData{Name: names[i], Value: value})
+		}
+		funnel.SetGlobalOptions(global...)
+		funnel.AddSeries(series, data, charts.WithLabelOpts(opts.Label{Show: true, Formatter: "{b}"}))
+		chart = funnel
+	default:
+		l.Error().Msg("Tipo de gráfica no soportado! Use bar, hbar, pie, treemap o funnel")
+		return common.NewConfigError("chart.type", config.Type)
+	}
+
+	l.Trace().Str("html-file", htmlname).Msg("Crear archivo html")
+	f, err := os.Create(htmlname)
+	if err != nil {
+		l.Error().Err(err).Msg("No se pudo crear archivo html!")
+		return err
+	}
+	defer f.Close()
+
+	l.Trace().Msg("Guardar en archivo")
+	return chart.Render(f)
+}
+
+// Versión estática de la gráfica, siempre como barras verticales
+func staticBar(config ChartConfig, items []ChartItem, title, subtitle string, logger zerolog.Logger) (*staticchart.BarChart, error) {
+	l := logger.With().Str("function", "staticBar").Logger()
+
+	names, values, err := chartData(config, items)
+	if err != nil {
+		l.Error().Str("values", config.Values).Msg("Valores a graficar no soportados! Use counts o scores")
+		return nil, err
+	}
+	if config.Type != ChartBar {
+		l.Debug().Str("type", config.Type).Msg("Las gráficas estáticas solo se dibujan como barras verticales")
+	}
+	title, subtitle = chartTitles(config, title, subtitle)
+	return &staticchart.BarChart{
+		Title:    title,
+		Subtitle: subtitle,
+		XLabel:   config.XAxisLabel,
+		YLabel:   config.YAxisLabel,
+		Labels:   names,
+		Values:   values,
+	}, nil
+}
+
+// Guarda la versión estática de la gráfica en los formatos de static
+func buildStatic(base string, config ChartConfig, static staticchart.Config, items []ChartItem, title, subtitle string, logger zerolog.Logger) ([]string, error) {
+	l := logger.With().Str("function", "buildStatic").Logger()
+
+	chart, err := staticBar(config, items, title, subtitle, l)
+	if err != nil {
+		return nil, err
+	}
+	l.Trace().Str("base", base).Msg("Dibujar gráfica estática")
+	return staticchart.Render(chart, base, static, l)
+}
@@ -0,0 +1,82 @@
+package resultproc
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestBuildChartTypes(t *testing.T) {
+	items := []ChartItem{
+		{Name: "go", Count: 300, Score: 100, Parts: []float64{60, 40}},
+		{Name: "rust", Count: 200, Score: 50, Parts: []float64{50, 0}},
+		{Name: "c", Count: 100, Score: 0},
+	}
+	tests := []struct {
+		chartType string
+		stack     []string
+		contains  []string
+	}{
+		{ChartBar, nil, []string{`"type":"bar"`, `"name":"Cantidad"`}},
+		{ChartHorizontalBar, nil, []string{`"type":"bar"`, `"data":["c","rust","go"]`}},
+		{ChartBar, []string{"A", "B"}, []string{`"name":"A"`, `"name":"B"`, `"stack":"total"`}},
+		{ChartPie, nil, []string{`"type":"pie"`}},
+		{ChartFunnel, nil, []string{`"type":"funnel"`}},
+		{ChartTreemap, nil, []string{`"type":"treemap"`, `{"name":"go","value":300}`}},
+		{ChartTreemap, []string{"A", "B"}, []string{`"type":"treemap"`, `{"name":"go","value":300,"children":[{"name":"A","value":60},{"name":"B","value":40}]}`, `{"name":"rust","value":200,"children":[{"name":"A","value":50}]}`}},
+	}
+	for _, test := range tests {
+		t.Run(test.chartType, func(t *testing.T) {
+			config := GetDefaultChartsConfig().Languages
+			config.Type = test.chartType
+			if err := config.Validate(); err != nil {
+				t.Fatal(err)
+			}
+			filename := filepath.Join(t.TempDir(), "chart.html")
+			if err := buildChart(filename, config, items, test.stack, "Título", "", zerolog.Nop()); err != nil {
+				t.Fatal(err)
+			}
+			data, err := os.ReadFile(filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range test.contains {
+				if !strings.Contains(string(data), want) {
+					t.Errorf("missing %v", want)
+				}
+			}
+		})
+	}
+}
+
+func TestChartInvalid(t *testing.T) {
+	for _, config := range []ChartConfig{{Type: "radar", Values: ChartCounts}, {Type: ChartBar, Values: "ranks"}} {
+		if err := config.Validate(); err == nil {
+			t.Errorf("%+v: expected error", config)
+		}
+	}
+}
+
+func TestChartTitles(t *testing.T) {
+	var languages LanguageResultList
+	var tags TagResultList
+	tests := []struct {
+		top               int
+		language, tagname string
+	}{
+		{10, "Top 10 tiobe en Github", "Top 10 tags"},
+		{0, "Tiobe en Github", "Tags"},
+	}
+	for _, test := range tests {
+		config := ChartConfig{Top: test.top}
+		if got := languages.title(config); got != test.language {
+			t.Errorf("top %d: got %q, want %q", test.top, got, test.language)
+		}
+		if got := tags.title(config); got != test.tagname {
+			t.Errorf("top %d: got %q, want %q", test.top, got, test.tagname)
+		}
+	}
+}
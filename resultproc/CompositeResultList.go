@@ -0,0 +1,202 @@
+package resultproc
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"webscraping/terminal"
+
+	"github.com/rs/zerolog"
+)
+
+type CompositeConfig struct {
+	Enabled        bool    `json:"enabled" yaml:"enabled"`
+	TiobeWeight    float64 `json:"tiobe_weight" yaml:"tiobe_weight"`
+	GithubWeight   float64 `json:"github_weight" yaml:"github_weight"`
+	InterestWeight float64 `json:"interest_weight" yaml:"interest_weight"`
+	HtmlFile       string  `json:"html_file" yaml:"html_file"`
+	ResultFile     string  `json:"result_file" yaml:"result_file"`
+}
+
+type CompositeInput struct {
+	Language         string
+	TiobeRating      float64
+	TopicNum         int32
+	InterestMentions int
+}
+
+type CompositeResult struct {
+	CompositeInput
+	Score float64
+	// Aporte de cada componente al puntaje, ya multiplicado por su peso
+	Tiobe, Github, Interest float64
+}
+
+type CompositeResultList struct {
+	Logger  zerolog.Logger
+	Config  CompositeConfig
+	results []CompositeResult
+}
+
+func GetDefaultCompositeConfig() CompositeConfig {
+	return CompositeConfig{
+		Enabled:        false,
+		TiobeWeight:    0.5,
+		GithubWeight:   0.5,
+		InterestWeight: 0,
+		HtmlFile:       "indice.html",
+		ResultFile:     "indice.csv",
+	}
+}
+
+func CreateCompositeResultList(inputs []CompositeInput, config CompositeConfig, logger zerolog.Logger) CompositeResultList {
+	var resl CompositeResultList
+	l := logger.With().Str("function", "CreateCompositeResultList").Logger()
+
+	l.Trace().Msg("Crear logger")
+	resl.Logger = logger.With().Str("struct", "CompositeResultList").Logger()
+	resl.Config = config
+
+	l.Trace().Msg("Normalizando componentes")
+	tiobe := make([]float64, len(inputs))
+	github := make([]float64, len(inputs))
+	interest := make([]float64, len(inputs))
+	for i, in := range inputs {
+		tiobe[i] = in.TiobeRating
+		github[i] = float64(in.TopicNum)
+		interest[i] = float64(in.InterestMentions)
+	}
+	tiobe = normalizeComponent(tiobe)
+	github = normalizeComponent(github)
+	interest = normalizeComponent(interest)
+
+	total := config.TiobeWeight + config.GithubWeight + config.InterestWeight
+	if total <= 0 {
+		l.Warn().Msg("La suma de pesos no es positiva, todos los puntajes serán 0")
+		total = 1
+	}
+
+	l.Trace().Msg("Calculando puntaje compuesto")
+	for i, in := range inputs {
+		res := CompositeResult{
+			CompositeInput: in,
+			Tiobe:          config.TiobeWeight * tiobe[i] / total,
+			Github:         config.GithubWeight * github[i] / total,
+			Interest:       config.InterestWeight * interest[i] / total,
+		}
+		res.Score = res.Tiobe + res.Github + res.Interest
+		resl.results = append(resl.results, res)
+	}
+	sort.SliceStable(resl.results, func(i, j int) bool {
+		return resl.results[i].Score > resl.results[j].Score
+	})
+
+	l.Trace().Msg("EXIT")
+	return resl
+}
+
+// Minmax de 0 a 100. Un componente sin variación (por ejemplo sin datos de tiobe) no aporta.
+func normalizeComponent(values []float64) []float64 {
+	for _, v := range values {
+		if v != values[0] {
+			return minmax(values)
+		}
+	}
+	return make([]float64, len(values))
+}
+
+func (resl *CompositeResultList) weights() string {
+	return fmt.Sprintf("tiobe: %v, github: %v, interés: %v", resl.Config.TiobeWeight, resl.Config.GithubWeight, resl.Config.InterestWeight)
+}
+
+// Guarda el ranking como csv. Los pesos se repiten en cada fila, como los metadatos de las exportaciones.
+func (resl *CompositeResultList) Save(filename string) error {
+	l := resl.Logger.With().Str("method", "Save").Logger()
+
+	l.Trace().Msg("Abriendo archivo")
+	file, err := os.Create(filename)
+	if err != nil {
+		l.Error().Err(err).Msg("No se pudo abrir ni crear archivo!")
+		return err
+	}
+	defer file.Close()
+
+	l.Trace().Msg("Guardando resultados")
+	writer := csv.NewWriter(file)
+	header := []string{"puesto", "lenguaje", "puntaje", "tiobe", "github", "interes", "peso_tiobe", "peso_github", "peso_interes"}
+	if err := writer.Write(header); err != nil {
+		l.Error().Err(err).Msg("No se pudo escribir en archivo")
+		return err
+	}
+	weights := []string{formatFloat(resl.Config.TiobeWeight), formatFloat(resl.Config.GithubWeight), formatFloat(resl.Config.InterestWeight)}
+	for i, res := range resl.results {
+		row := []string{strconv.Itoa(i + 1), res.Language, formatFloat(res.Score), formatFloat(res.Tiobe), formatFloat(res.Github), formatFloat(res.Interest)}
+		if err := writer.Write(append(row, weights...)); err != nil {
+			l.Error().Err(err).Msg("No se pudo escribir en archivo")
+			return err
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		l.Error().Err(err).Msg("No se pudo escribir en archivo")
+		return err
+	}
+	return nil
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+func (resl *CompositeResultList) Graph(htmlname string, config ChartConfig) error {
+	l := resl.Logger.With().Str("method", "Graph").Logger()
+
+	l.Trace().Msg("Crear gráfica")
+	items := make([]ChartItem, 0, len(resl.results))
+	for _, res := range resl.results {
+		items = append(items, ChartItem{
+			Name:  res.Language,
+			Count: res.Score,
+			Score: res.Score,
+			Parts: []float64{res.Tiobe, res.Github, res.Interest},
+		})
+	}
+	return buildChart(htmlname, config, items, []string{"Tiobe", "Github", "Interés"}, "Índice compuesto", fmt.Sprintf("pesos: %v", resl.weights()), l)
+}
+
+// Tabla para la terminal, con barras según el puntaje compuesto
+func (resl *CompositeResultList) TerminalTable() *terminal.Table {
+	table := terminal.Table{
+		Title: fmt.Sprintf("Índice compuesto (pesos: %v)", resl.weights()),
+		Columns: []terminal.Column{
+			{Name: "#", Align: terminal.AlignRight},
+			{Name: "Lenguaje", Align: terminal.AlignLeft},
+			{Name: "Puntaje", Align: terminal.AlignRight},
+			{Name: "Tiobe", Align: terminal.AlignRight},
+			{Name: "Github", Align: terminal.AlignRight},
+			{Name: "Interés", Align: terminal.AlignRight},
+		},
+	}
+	for i, res := range resl.results {
+		table.Rows = append(table.Rows, []string{
+			fmt.Sprint(i + 1), res.Language, terminal.Float(res.Score, 2),
+			terminal.Float(res.Tiobe, 2), terminal.Float(res.Github, 2), terminal.Float(res.Interest, 2),
+		})
+		table.Bars = append(table.Bars, res.Score)
+	}
+	return &table
+}
+
+func (resl *CompositeResultList) String() string {
+	if resl == nil {
+		return ""
+	}
+	var sb strings.Builder
+	for i, res := range resl.results {
+		sb.WriteString(fmt.Sprintf("%3d %30s, %10.4f = tiobe %10.4f + github %10.4f + interés %10.4f\n", i+1, res.Language, res.Score, res.Tiobe, res.Github, res.Interest))
+	}
+	return sb.String()
+}
@@ -0,0 +1,68 @@
+package resultproc
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func testComposite() CompositeResultList {
+	config := GetDefaultCompositeConfig()
+	config.InterestWeight = 1
+	inputs := []CompositeInput{
+		{Language: "go", TiobeRating: 2, TopicNum: 300, InterestMentions: 10},
+		{Language: "c", TiobeRating: 10, TopicNum: 100, InterestMentions: 0},
+		{Language: "rust", TiobeRating: 1, TopicNum: 200, InterestMentions: 5},
+	}
+	return CreateCompositeResultList(inputs, config, zerolog.Nop())
+}
+
+func TestCompositeSave(t *testing.T) {
+	res := testComposite()
+	filename := filepath.Join(t.TempDir(), "indice.csv")
+	if err := res.Save(filename); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// go: tiobe 1/9*25, github 100*25/100, interés 100*50/100
+	want := strings.Join([]string{
+		"puesto,lenguaje,puntaje,tiobe,github,interes,peso_tiobe,peso_github,peso_interes",
+		"1,go,77.77777777777777,2.7777777777777777,25,50,0.5,0.5,1",
+		"2,rust,37.5,0,12.5,25,0.5,0.5,1",
+		"3,c,25,25,0,0,0.5,0.5,1",
+		"",
+	}, "\n")
+	if string(data) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", data, want)
+	}
+}
+
+func TestCompositeGraph(t *testing.T) {
+	res := testComposite()
+	config := GetDefaultChartsConfig().Composite
+	for _, chartType := range []string{ChartBar, ChartHorizontalBar, ChartPie} {
+		config.Type = chartType
+		filename := filepath.Join(t.TempDir(), "indice.html")
+		if err := res.Graph(filename, config); err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		html := string(data)
+		if !strings.Contains(html, "Índice compuesto") || !strings.Contains(html, "1920px") {
+			t.Errorf("%v: missing title or size", chartType)
+		}
+		stacked := strings.Contains(html, `"stack":"total"`)
+		if stacked != (chartType != ChartPie) {
+			t.Errorf("%v: stacked = %v", chartType, stacked)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package resultproc
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"webscraping/terminal"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/opts"
+	"github.com/rs/zerolog"
+)
+
+// Estado de una entrada al comparar dos corridas
+const (
+	DeltaNew     = "nuevo"
+	DeltaDropped = "eliminado"
+)
+
+type DeltaResult struct {
+	Name     string
+	Old, New float64
+	Change   float64
+	// Cambio porcentual, NaN si no existía en la corrida anterior
+	Percent          float64
+	OldRank, NewRank int
+	// Puestos subidos (positivo) o bajados (negativo)
+	RankMove int
+	Status   string
+}
+
+type DeltaResultList struct {
+	Logger  zerolog.Logger
+	Title   string
+	results []DeltaResult
+}
+
+// Las entradas de missing no se pudieron leer o se descartaron en alguna de las corridas. No se
+// comparan, y para no mover los puestos de las demás toman el valor de la otra corrida.
+func CreateDeltaResultList(old, new map[string]float64, missing []string, logger zerolog.Logger) DeltaResultList {
+	var resl DeltaResultList
+	l := logger.With().Str("function", "CreateDeltaResultList").Logger()
+
+	l.Trace().Msg("Crear logger")
+	resl.Logger = logger.With().Str("struct", "DeltaResultList").Logger()
+
+	l.Trace().Msg("Completar entradas faltantes")
+	skip := make(map[string]bool)
+	before := make(map[string]float64, len(old))
+	for name, value := range old {
+		before[name] = value
+	}
+	after := make(map[string]float64, len(new))
+	for name, value := range new {
+		after[name] = value
+	}
+	for _, name := range missing {
+		skip[name] = true
+		if value, ok := new[name]; ok {
+			if _, ok := before[name]; !ok {
+				before[name] = value
+			}
+		}
+		if value, ok := old[name]; ok {
+			if _, ok := after[name]; !ok {
+				after[name] = value
+			}
+		}
+	}
+	old, new = before, after
+
+	l.Trace().Msg("Calcular puestos")
+	oldranks := ranks(old)
+	newranks := ranks(new)
+
+	l.Trace().Msg("Comparar entradas")
+	for name, newval := range new {
+		if skip[name] {
+			l.Debug().Str("name", name).Msg("Sin datos en alguna de las corridas, saltando")
+			continue
+		}
+		res := DeltaResult{Name: name, New: newval, NewRank: newranks[name]}
+		if oldval, ok := old[name]; ok {
+			res.Old = oldval
+			res.OldRank = oldranks[name]
+			res.RankMove = res.OldRank - res.NewRank
+			res.Percent = math.NaN()
+			if oldval != 0 {
+				res.Percent = (newval - oldval) / oldval * 100
+			}
+		} else {
+			res.Status = DeltaNew
+			res.Percent = math.NaN()
+		}
+		res.Change = res.New - res.Old
+		resl.results = append(resl.results, res)
+	}
+	for name, oldval := range old {
+		if _, ok := new[name]; ok || skip[name] {
+			continue
+		}
+		resl.results = append(resl.results, DeltaResult{
+			Name:    name,
+			Old:     oldval,
+			OldRank: oldranks[name],
+			Change:  -oldval,
+			Percent: -100,
+			Status:  DeltaDropped,
+		})
+	}
+
+	l.Trace().Msg("Ordenar por cambio")
+	sort.Slice(resl.results, func(i, j int) bool {
+		if resl.results[i].Change != resl.results[j].Change {
+			return resl.results[i].Change > resl.results[j].Change
+		}
+		return resl.results[i].Name < resl.results[j].Name
+	})
+
+	l.Trace().Msg("EXIT")
+	return resl
+}
+
+// Puesto de cada entrada ordenando de mayor a menor valor
+func ranks(values map[string]float64) map[string]int {
+	ret := make(map[string]int)
+	for i, name := range sortedFloatKeys(values) {
+		ret[name] = i + 1
+	}
+	return ret
+}
+
+func (resl *DeltaResultList) Results() []DeltaResult {
+	return resl.results
+}
+
+func (resl *DeltaResultList) Graph(htmlname string) error {
+	l := resl.Logger.With().Str("method", "Graph").Logger()
+
+	l.Trace().Msg("Crear nueva gráfica")
+	bar := charts.NewBar()
+
+	l.Trace().Msg("Configurar opciones")
+	bar.SetGlobalOptions(
+		charts.WithTitleOpts(opts.Title{
+			Title: resl.Title,
+		}),
+		charts.WithTooltipOpts(opts.Tooltip{Show: true}),
+		charts.WithInitializationOpts(opts.Initialization{
+			Width:  "1920px",
+			Height: fmt.Sprintf("%dpx", 200+25*len(resl.results)),
+		}),
+	)
+
+	l.Trace().Msg("Llenar datos")
+	var names []string
+	bars := make([]opts.BarData, 0)
+	// Se agrega al revés para que el mayor aumento quede arriba
+	for i := len(resl.results) - 1; i >= 0; i-- {
+		res := resl.results[i]
+		color := "#3ba272"
+		if res.Change < 0 {
+			color = "#ee6666"
+		}
+		names = append(names, res.Name)
+		bars = append(bars, opts.BarData{Value: res.Change, ItemStyle: &opts.ItemStyle{Color: color}})
+	}
+	bar.SetXAxis(names).
+		AddSeries("Cambio", bars).
+		XYReversal()
+
+	l.Trace().Str("html-file", htmlname).Msg("Crear archivo html")
+	f, err := os.Create(htmlname)
+	if err != nil {
+		l.Error().Err(err).Msg("No se pudo crear archivo html!")
+		return err
+	}
+	defer f.Close()
+
+	l.Trace().Msg("Guardar en archivo")
+	return bar.Render(f)
+}
+
+// Tabla para la terminal, con barras divergentes según el cambio
+func (resl *DeltaResultList) TerminalTable() *terminal.Table {
+	table := terminal.Table{
+		Title: resl.Title,
+		Columns: []terminal.Column{
+			{Name: "#", Align: terminal.AlignRight},
+			{Name: "Nombre", Align: terminal.AlignLeft},
+			{Name: "Anterior", Align: terminal.AlignRight},
+			{Name: "Actual", Align: terminal.AlignRight},
+			{Name: "Cambio", Align: terminal.AlignRight},
+			{Name: "%", Align: terminal.AlignRight},
+			{Name: "Puesto", Align: terminal.AlignRight},
+			{Name: "Movimiento", Align: terminal.AlignLeft},
+		},
+	}
+	for i, res := range resl.results {
+		percent := "-"
+		if !math.IsNaN(res.Percent) {
+			percent = fmt.Sprintf("%+.2f", res.Percent)
+		}
+		move := ""
+		switch {
+		case res.Status != "":
+			move = res.Status
+		case res.RankMove != 0:
+			move = fmt.Sprintf("%+d", res.RankMove)
+		}
+		table.Rows = append(table.Rows, []string{
+			fmt.Sprint(i + 1), res.Name, terminal.Float(res.Old, 2), terminal.Float(res.New, 2), fmt.Sprintf("%+.2f", res.Change),
+			percent, fmt.Sprintf("%d -> %d", res.OldRank, res.NewRank), move,
+		})
+		table.Bars = append(table.Bars, res.Change)
+	}
+	return &table
+}
+
+func (res *DeltaResult) String() string {
+	if res == nil {
+		return ""
+	}
+	percent := "-"
+	if !math.IsNaN(res.Percent) {
+		percent = fmt.Sprintf("%+.2f%%", res.Percent)
+	}
+	rank := "-"
+	switch {
+	case res.Status != "":
+		rank = res.Status
+	case res.RankMove != 0:
+		rank = fmt.Sprintf("%+d", res.RankMove)
+	}
+	return fmt.Sprintf("%30s, %12.2f, %12.2f, %+12.2f, %10s, %4d -> %4d, %10s", res.Name, res.Old, res.New, res.Change, percent, res.OldRank, res.NewRank, rank)
+}
+
+func (resl *DeltaResultList) String() string {
+	if resl == nil {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%30s, %12s, %12s, %12s, %10s, %12s, %10s\n", "nombre", "anterior", "actual", "cambio", "%", "puesto", "movimiento"))
+	for _, res := range resl.results {
+		sb.WriteString(res.String())
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,45 @@
+package resultproc
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func deltas(resl DeltaResultList) string {
+	var s string
+	for _, res := range resl.Results() {
+		s += fmt.Sprintf("%v %v %d->%d %v; ", res.Name, res.Change, res.OldRank, res.NewRank, res.Status)
+	}
+	return s
+}
+
+func TestDeltaResultList(t *testing.T) {
+	old := map[string]float64{"python": 400, "go": 300, "c": 200}
+	new := map[string]float64{"python": 450, "go": 250, "rust": 100}
+	got := deltas(CreateDeltaResultList(old, new, nil, zerolog.Nop()))
+	// rust es nuevo: el cambio es su valor completo
+	want := "rust 100 0->3 nuevo; python 50 1->1 ; go -50 2->2 ; c -200 3->0 eliminado; "
+	if got != want {
+		t.Errorf("got %v\nwant %v", got, want)
+	}
+}
+
+func TestDeltaResultListMissing(t *testing.T) {
+	old := map[string]float64{"python": 400, "go": 300, "c": 200}
+	// go no se pudo leer en la corrida nueva: no es eliminado y c no sube de puesto
+	new := map[string]float64{"python": 450, "c": 210}
+	got := deltas(CreateDeltaResultList(old, new, []string{"go"}, zerolog.Nop()))
+	want := "python 50 1->1 ; c 10 3->3 ; "
+	if got != want {
+		t.Errorf("got %v\nwant %v", got, want)
+	}
+
+	// Cuando vuelve no es nuevo
+	got = deltas(CreateDeltaResultList(new, old, []string{"go"}, zerolog.Nop()))
+	want = "c -10 3->3 ; python -50 1->1 ; "
+	if got != want {
+		t.Errorf("got %v\nwant %v", got, want)
+	}
+}
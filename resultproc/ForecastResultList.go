@@ -0,0 +1,120 @@
+package resultproc
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"webscraping/forecast"
+
+	"github.com/rs/zerolog"
+)
+
+type ForecastResult struct {
+	Name     string
+	Last     float64
+	LastRank int
+	// Proyección semana a semana, la última es la del horizonte
+	Path []forecast.Prediction
+	Rank int
+}
+
+type ForecastResultList struct {
+	Logger  zerolog.Logger
+	Model   string
+	Weeks   int
+	Times   []time.Time
+	results []ForecastResult
+}
+
+func CreateForecastResultList(points []HistoryPoint, forecaster forecast.Forecaster, weeks int, logger zerolog.Logger) ForecastResultList {
+	var resl ForecastResultList
+	l := logger.With().Str("function", "CreateForecastResultList").Logger()
+
+	l.Trace().Msg("Crear logger")
+	resl.Logger = logger.With().Str("struct", "ForecastResultList").Logger()
+	resl.Model = forecaster.Name()
+	resl.Weeks = weeks
+	if len(points) == 0 || weeks < 1 {
+		l.Trace().Msg("Sin corridas o sin horizonte, no hay pronóstico")
+		return resl
+	}
+
+	points = append([]HistoryPoint{}, points...)
+	sort.SliceStable(points, func(i, j int) bool { return points[i].Time.Before(points[j].Time) })
+	origin := points[0].Time
+	last := points[len(points)-1]
+
+	l.Trace().Msg("Calcular tiempos del pronóstico")
+	var at []float64
+	for week := 1; week <= weeks; week++ {
+		moment := last.Time.AddDate(0, 0, 7*week)
+		resl.Times = append(resl.Times, moment)
+		at = append(at, moment.Sub(origin).Hours()/24)
+	}
+
+	l.Trace().Msg("Pronosticar cada serie presente en la última corrida")
+	lastranks := ranks(last.Values)
+	projected := make(map[string]float64)
+	for name, value := range last.Values {
+		var t, y []float64
+		for _, point := range points {
+			if v, ok := point.Values[name]; ok {
+				t = append(t, point.Time.Sub(origin).Hours()/24)
+				y = append(y, v)
+			}
+		}
+		path, err := forecaster.Forecast(t, y, at)
+		if err != nil {
+			l.Debug().Err(err).Str("serie", name).Msg("No se puede pronosticar la serie, saltando...")
+			continue
+		}
+		resl.results = append(resl.results, ForecastResult{Name: name, Last: value, LastRank: lastranks[name], Path: path})
+		projected[name] = path[len(path)-1].Value
+	}
+
+	l.Trace().Msg("Calcular puestos proyectados")
+	projectedranks := ranks(projected)
+	for i := range resl.results {
+		resl.results[i].Rank = projectedranks[resl.results[i].Name]
+	}
+	sort.Slice(resl.results, func(i, j int) bool { return resl.results[i].Rank < resl.results[j].Rank })
+
+	l.Trace().Msg("EXIT")
+	return resl
+}
+
+func (resl *ForecastResultList) Results() []ForecastResult {
+	return resl.results
+}
+
+func (resl *ForecastResultList) Get(name string) *ForecastResult {
+	for i := range resl.results {
+		if resl.results[i].Name == name {
+			return &resl.results[i]
+		}
+	}
+	return nil
+}
+
+func (res *ForecastResult) String() string {
+	if res == nil || len(res.Path) == 0 {
+		return ""
+	}
+	end := res.Path[len(res.Path)-1]
+	return fmt.Sprintf("%30s, %12.2f, %12.2f, [%12.2f, %12.2f], %4d -> %4d", res.Name, res.Last, end.Value, end.Lower, end.Upper, res.LastRank, res.Rank)
+}
+
+func (resl *ForecastResultList) String() string {
+	if resl == nil {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Pronóstico a %d semanas, modelo %v\n", resl.Weeks, resl.Model))
+	sb.WriteString(fmt.Sprintf("%30s, %12s, %12s, %29s, %12s\n", "nombre", "actual", "pronóstico", "intervalo", "puesto"))
+	for _, res := range resl.results {
+		sb.WriteString(res.String())
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
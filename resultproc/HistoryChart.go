@@ -0,0 +1,266 @@
+package resultproc
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"time"
+	"webscraping/staticchart"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/opts"
+	"github.com/rs/zerolog"
+)
+
+type HistoryPoint struct {
+	Time   time.Time
+	Values map[string]float64
+}
+
+type HistoryChart struct {
+	Logger     zerolog.Logger
+	Title      string
+	Normalized bool
+	labels     []string
+	names      []string
+	// Un valor por corrida, los faltantes son nil
+	series map[string][]*float64
+	// Base de la normalización de cada serie
+	bases     map[string]float64
+	forecasts *ForecastResultList
+}
+
+func CreateHistoryChart(points []HistoryPoint, topn int, normalized bool, logger zerolog.Logger) HistoryChart {
+	var chart HistoryChart
+	l := logger.With().Str("function", "CreateHistoryChart").Logger()
+
+	l.Trace().Msg("Crear logger")
+	chart.Logger = logger.With().Str("struct", "HistoryChart").Logger()
+	chart.Normalized = normalized
+	chart.series = make(map[string][]*float64)
+	chart.bases = make(map[string]float64)
+
+	l.Trace().Msg("Ordenar corridas por fecha")
+	points = append([]HistoryPoint{}, points...)
+	sort.SliceStable(points, func(i, j int) bool { return points[i].Time.Before(points[j].Time) })
+
+	l.Trace().Msg("Armar series")
+	latest := make(map[string]float64)
+	for i, point := range points {
+		chart.labels = append(chart.labels, point.Time.Local().Format("2006-01-02 15:04"))
+		for name, value := range point.Values {
+			if chart.series[name] == nil {
+				chart.series[name] = make([]*float64, len(points))
+			}
+			value := value
+			chart.series[name][i] = &value
+			latest[name] = value
+		}
+	}
+
+	l.Trace().Int("top", topn).Msg("Elegir series con mayor valor en la última corrida")
+	chart.names = sortedFloatKeys(latest)
+	if topn > 0 && len(chart.names) > topn {
+		chart.names = chart.names[0:topn]
+	}
+
+	if normalized {
+		l.Trace().Msg("Normalizando series a 100 en la primera corrida")
+		for _, name := range chart.names {
+			chart.bases[name] = normalizeSeries(chart.series[name])
+		}
+	}
+
+	l.Trace().Msg("EXIT")
+	return chart
+}
+
+func sortedFloatKeys(values map[string]float64) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if values[keys[i]] != values[keys[j]] {
+			return values[keys[i]] > values[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+// Índice base 100 sobre el primer valor distinto de cero de la serie
+func normalizeSeries(series []*float64) float64 {
+	var base float64
+	for _, value := range series {
+		if value != nil && *value != 0 {
+			base = *value
+			break
+		}
+	}
+	if base == 0 {
+		return 0
+	}
+	for _, value := range series {
+		if value != nil {
+			*value = *value / base * 100
+		}
+	}
+	return base
+}
+
+func (chart *HistoryChart) scale(name string, value float64) float64 {
+	if !chart.Normalized || chart.bases[name] == 0 {
+		return value
+	}
+	return value / chart.bases[name] * 100
+}
+
+// Agrega el pronóstico como extensión punteada de cada serie graficada
+func (chart *HistoryChart) SetForecast(forecasts *ForecastResultList) {
+	chart.forecasts = forecasts
+}
+
+func (chart *HistoryChart) forecastData(name string) []opts.LineData {
+	res := chart.forecasts.Get(name)
+	if res == nil {
+		return nil
+	}
+	history := chart.series[name]
+	data := make([]opts.LineData, len(history), len(history)+len(res.Path))
+	for i := range data {
+		data[i] = opts.LineData{Value: "-"}
+	}
+	// Se repite el último valor observado para que la línea punteada continúe la serie
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i] != nil {
+			data[i] = opts.LineData{Value: *history[i]}
+			break
+		}
+	}
+	for _, prediction := range res.Path {
+		data = append(data, opts.LineData{Value: chart.scale(name, prediction.Value)})
+	}
+	return data
+}
+
+func (chart *HistoryChart) lineData(series []*float64) []opts.LineData {
+	data := make([]opts.LineData, 0, len(series))
+	for _, value := range series {
+		if value == nil {
+			// echarts interpreta "-" como valor faltante
+			data = append(data, opts.LineData{Value: "-"})
+		} else {
+			data = append(data, opts.LineData{Value: *value})
+		}
+	}
+	return data
+}
+
+func (chart *HistoryChart) subtitle() string {
+	subtitle := "valores absolutos"
+	if chart.Normalized {
+		subtitle = "índice, primera corrida = 100"
+	}
+	if chart.forecasts != nil {
+		subtitle = fmt.Sprintf("%v, pronóstico %v a %d semanas", subtitle, chart.forecasts.Model, chart.forecasts.Weeks)
+	}
+	return fmt.Sprintf("%d corridas, %v", len(chart.labels), subtitle)
+}
+
+// Fechas de las corridas seguidas de las del pronóstico
+func (chart *HistoryChart) axisLabels() []string {
+	labels := append([]string{}, chart.labels...)
+	if chart.forecasts != nil {
+		for _, moment := range chart.forecasts.Times {
+			labels = append(labels, moment.Local().Format("2006-01-02 15:04"))
+		}
+	}
+	return labels
+}
+
+func (chart *HistoryChart) Graph(htmlname string) error {
+	l := chart.Logger.With().Str("method", "Graph").Logger()
+
+	l.Trace().Msg("Crear nueva gráfica")
+	line := charts.NewLine()
+
+	labels := chart.axisLabels()
+
+	l.Trace().Msg("Configurar opciones")
+	line.SetGlobalOptions(
+		charts.WithTitleOpts(opts.Title{
+			Title:    chart.Title,
+			Subtitle: chart.subtitle(),
+		}),
+		charts.WithLegendOpts(opts.Legend{Show: true, Right: "10%"}),
+		charts.WithTooltipOpts(opts.Tooltip{Show: true, Trigger: "axis"}),
+		charts.WithDataZoomOpts(opts.DataZoom{
+			Type:  "slider",
+			Start: 0,
+			End:   100,
+		}),
+		charts.WithInitializationOpts(opts.Initialization{
+			Width:  "1920px",
+			Height: "600px",
+		}),
+	)
+
+	l.Trace().Msg("Llenar datos")
+	line.SetXAxis(labels)
+	for _, name := range chart.names {
+		line.AddSeries(name, chart.lineData(chart.series[name]),
+			charts.WithLineChartOpts(opts.LineChart{ConnectNulls: true}))
+		if chart.forecasts == nil {
+			continue
+		}
+		if data := chart.forecastData(name); data != nil {
+			line.AddSeries(name+" (pronóstico)", data,
+				charts.WithLineStyleOpts(opts.LineStyle{Type: "dashed"}))
+		}
+	}
+
+	l.Trace().Str("html-file", htmlname).Msg("Crear archivo html")
+	f, err := os.Create(htmlname)
+	if err != nil {
+		l.Error().Err(err).Msg("No se pudo crear archivo html!")
+		return err
+	}
+	defer f.Close()
+
+	l.Trace().Msg("Guardar en archivo")
+	return line.Render(f)
+}
+
+// Gráfica sin javascript, en los formatos de static. El pronóstico se dibuja punteado con el color de su serie.
+func (chart *HistoryChart) StaticGraph(base string, static staticchart.Config) ([]string, error) {
+	l := chart.Logger.With().Str("method", "StaticGraph").Logger()
+
+	l.Trace().Msg("Armar series")
+	line := staticchart.LineChart{
+		Title:    chart.Title,
+		Subtitle: chart.subtitle(),
+		Labels:   chart.axisLabels(),
+	}
+	for i, name := range chart.names {
+		color := staticchart.Color(i)
+		line.Series = append(line.Series, staticchart.Series{Name: name, Values: chart.series[name], Color: color})
+		if chart.forecasts == nil {
+			continue
+		}
+		if data := chart.forecastData(name); data != nil {
+			values := make([]*float64, 0, len(data))
+			for _, point := range data {
+				if value, ok := point.Value.(float64); ok {
+					values = append(values, &value)
+				} else {
+					values = append(values, nil)
+				}
+			}
+			line.Series = append(line.Series, staticchart.Series{Name: name + " (pronóstico)", Values: values, Dashed: true, Color: color})
+		}
+	}
+
+	l.Trace().Str("base", base).Msg("Dibujar gráfica estática")
+	return staticchart.Render(&line, base, static, l)
+}
@@ -2,123 +2,96 @@ package resultproc
 
 import (
 	"fmt"
-	"math"
-	"os"
 	"sort"
 	"strings"
+	"webscraping/export"
+	"webscraping/staticchart"
+	"webscraping/terminal"
 
-	"github.com/go-echarts/go-echarts/v2/charts"
-	"github.com/go-echarts/go-echarts/v2/opts"
 	"github.com/rs/zerolog"
 )
 
 type LanguageResultList struct {
 	Logger  zerolog.Logger
+	Scorer  string
 	results []LanguageResult
 }
 
-func CreateLanguageResultList(results map[string]int32, logger zerolog.Logger) LanguageResultList {
+func CreateLanguageResultList(results map[string]int32, scorer Scorer, logger zerolog.Logger) LanguageResultList {
 	var resl LanguageResultList
 	l := logger.With().Str("function", "CreateLanguageResultList").Logger()
 
 	l.Trace().Msg("Creando logger")
 	resl.Logger = logger.With().Str("struct", "ResultList").Logger()
 
-	var min, max int32
-	min = math.MaxInt32
-	max = 0
-	l.Trace().Msg("Calculando minimo and maximo")
-	for _, num := range results {
-		if num > max {
-			max = num
-		}
-		if num < min {
-			min = num
-		}
-	}
-	fmt.Print(min, max, "\n")
-
 	l.Trace().Msg("Parseando mapa a lista")
 	for lang, num := range results {
 		resl.results = append(resl.results, LanguageResult{
 			Logger:   logger.With().Str("object", "Result").Logger(),
-			Min:      min,
-			Max:      max,
 			TopicNum: num,
 			Language: lang,
 		})
 	}
 
+	l.Trace().Str("algoritmo", scorer.Name()).Msg("Calculando puntajes")
+	resl.Scorer = scorer.Name()
+	values := make([]float64, len(resl.results))
+	for i, res := range resl.results {
+		values[i] = float64(res.TopicNum)
+	}
+	for i, score := range scorer.Score(values) {
+		resl.results[i].Score = float32(score)
+	}
+
 	l.Trace().Msg("EXIT")
 	return resl
 }
 
-func (resl *LanguageResultList) Save(filename string) error {
-	l := resl.Logger.With().Str("method", "Save").Logger()
+// Tabla para exportar, en el orden actual de la lista
+func (resl *LanguageResultList) Table(meta export.Metadata) *export.Table {
+	l := resl.Logger.With().Str("method", "Table").Logger()
 
-	l.Trace().Msg("Abriendo archivo")
-	file, err := os.Create(filename)
-	if err != nil {
-		l.Error().Err(err).Msg("No se pudo abrir ni crear archivo!")
-		return err
+	l.Trace().Msg("Armando tabla de resultados")
+	meta.Scorer = resl.Scorer
+	table := export.Table{
+		Metadata: meta,
+		Columns:  []string{"rank", "language", "score", "topics"},
 	}
-	defer file.Close()
-
-	l.Trace().Msg("Guardando resultando")
-	for _, res := range resl.results {
-		err = res.Save(file)
-		if err != nil {
-			l.Error().Err(err).Msg("No se pudo guardar resultado!")
-			return err
-		}
+	for i, res := range resl.results {
+		table.Rows = append(table.Rows, []interface{}{i + 1, res.Language, float64(res.Score), res.TopicNum})
 	}
-	return nil
+	return &table
 }
 
-func (resl *LanguageResultList) Graph(htmlname string) error {
+func (resl *LanguageResultList) Graph(htmlname string, config ChartConfig) error {
 	l := resl.Logger.With().Str("method", "Graph").Logger()
 
-	l.Trace().Msg("Crear nueva gráfica")
-	bar := charts.NewBar()
-
-	l.Trace().Msg("Configurar opciones")
-	bar.SetGlobalOptions(
-		charts.WithTitleOpts(opts.Title{
-			Title: "Top 20 tiobe en Github",
-		}),
-		charts.WithDataZoomOpts(opts.DataZoom{
-			Type:  "slider",
-			Start: 0,
-			End:   100,
-		}),
-		charts.WithInitializationOpts(opts.Initialization{
-			Width:  "1920px",
-			Height: "600px",
-		}),
-	)
-
-	l.Trace().Msg("Llenar datos")
-	Languagelist := resl.getLanguageList()
-	if len(Languagelist) > 10 {
-		Languagelist = Languagelist[0:10]
-	}
-	barlist := resl.getBars()
-	if len(barlist) > 10 {
-		barlist = barlist[0:10]
-	}
-	bar.SetXAxis(Languagelist).
-		AddSeries("Languages", barlist)
-
-	l.Trace().Str("html-file", htmlname).Msg("Crear archivo html")
-	f, err := os.Create(htmlname)
-	if err != nil {
-		l.Error().Err(err).Msg("No se pudo crear archivo html!")
-		return err
+	l.Trace().Msg("Crear gráfica")
+	return buildChart(htmlname, config, resl.getChartItems(), nil, resl.title(config), fmt.Sprintf("puntaje: %v", resl.Scorer), l)
+}
+
+// Gráfica sin javascript, en los formatos de static. Retorna los archivos creados.
+func (resl *LanguageResultList) StaticGraph(base string, config ChartConfig, static staticchart.Config) ([]string, error) {
+	l := resl.Logger.With().Str("method", "StaticGraph").Logger()
+
+	l.Trace().Msg("Crear gráfica estática")
+	return buildStatic(base, config, static, resl.getChartItems(), resl.title(config), fmt.Sprintf("puntaje: %v", resl.Scorer), l)
+}
+
+// Gráfica sin javascript, para incluir en otros reportes
+func (resl *LanguageResultList) StaticChart(config ChartConfig) (staticchart.Chart, error) {
+	return staticBar(config, resl.getChartItems(), resl.title(config), fmt.Sprintf("puntaje: %v", resl.Scorer), resl.Logger)
+}
+
+func (resl *LanguageResultList) title(config ChartConfig) string {
+	if config.Top <= 0 {
+		return "Tiobe en Github"
 	}
+	return fmt.Sprintf("Top %d tiobe en Github", config.Top)
+}
 
-	l.Trace().Msg("Guardar en archivo")
-	bar.Render(f)
-	return nil
+func (resl *LanguageResultList) Results() []LanguageResult {
+	return resl.results
 }
 
 func (resl *LanguageResultList) ScoreSort() {
@@ -135,42 +108,35 @@ func (resl *LanguageResultList) NumSort() {
 	sort.Sort(sort.Reverse(NumSort(resl.results)))
 }
 
-func (resl *LanguageResultList) getLanguageList() []string {
-	l := resl.Logger.With().Str("method", "getLanguageList").Logger()
+func (resl *LanguageResultList) getChartItems() []ChartItem {
+	l := resl.Logger.With().Str("method", "getChartItems").Logger()
 
-	l.Trace().Msg("Obtener array de lenguajes")
-	var langs []string
+	l.Trace().Msg("Obtener slice de elementos a graficar")
+	items := make([]ChartItem, 0, len(resl.results))
 	for _, res := range resl.results {
-		langs = append(langs, res.Language)
+		items = append(items, ChartItem{Name: res.Language, Count: float64(res.TopicNum), Score: float64(res.Score)})
 	}
-
-	l.Trace().Msg("Retornar slice de lenguajes")
-	return langs
+	return items
 }
-func (resl *LanguageResultList) getValueList() []int32 {
-	l := resl.Logger.With().Str("method", "getValueList").Logger()
 
-	l.Trace().Msg("Obtener slice de valores")
-	var topicnums []int32
-	for _, res := range resl.results {
-		topicnums = append(topicnums, res.TopicNum)
+// Tabla para la terminal, con barras según la cantidad de repositorios
+func (resl *LanguageResultList) TerminalTable() *terminal.Table {
+	table := terminal.Table{
+		Title: fmt.Sprintf("Lenguajes en Github (puntaje: %v)", resl.Scorer),
+		Columns: []terminal.Column{
+			{Name: "#", Align: terminal.AlignRight},
+			{Name: "Lenguaje", Align: terminal.AlignLeft},
+			{Name: "Puntaje", Align: terminal.AlignRight},
+			{Name: "Repositorios", Align: terminal.AlignRight},
+		},
 	}
-
-	l.Trace().Msg("Retornar slice de valores int32")
-	return topicnums
-}
-func (resl *LanguageResultList) getBars() []opts.BarData {
-	l := resl.Logger.With().Str("method", "getBars").Logger()
-
-	l.Trace().Msg("Obtener slice de barras")
-	bars := make([]opts.BarData, 0)
-	for _, num := range resl.getValueList() {
-		bars = append(bars, opts.BarData{Value: num})
+	for i, res := range resl.results {
+		table.Rows = append(table.Rows, []string{fmt.Sprint(i + 1), res.Language, terminal.Float(float64(res.Score), 2), terminal.Count(int64(res.TopicNum))})
+		table.Bars = append(table.Bars, float64(res.TopicNum))
 	}
-
-	l.Trace().Msg("Retornar slice de barras")
-	return bars
+	return &table
 }
+
 func (resl *LanguageResultList) String() string {
 	if resl == nil {
 		return ""
@@ -0,0 +1,219 @@
+package resultproc
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/opts"
+	"github.com/rs/zerolog"
+)
+
+type RankPair struct {
+	Language    string
+	TiobeRank   int
+	TiobeRating float64
+	TopicNum    int32
+	GithubRank  int
+}
+
+type RankCorrelation struct {
+	Logger   zerolog.Logger
+	Spearman float64
+	Kendall  float64
+	pairs    []RankPair
+}
+
+// Compara el orden de tiobe con el orden por repositorios en github, solo para lenguajes con ambos datos
+func CreateRankCorrelation(tioberanks map[string]int, ratings map[string]float64, counts map[string]int32, logger zerolog.Logger) RankCorrelation {
+	var corr RankCorrelation
+	l := logger.With().Str("function", "CreateRankCorrelation").Logger()
+
+	l.Trace().Msg("Crear logger")
+	corr.Logger = logger.With().Str("struct", "RankCorrelation").Logger()
+
+	l.Trace().Msg("Unir lenguajes con ambos datos")
+	githubvalues := make(map[string]float64)
+	for lang, rank := range tioberanks {
+		num, ok := counts[lang]
+		if !ok {
+			continue
+		}
+		githubvalues[lang] = float64(num)
+		corr.pairs = append(corr.pairs, RankPair{Language: lang, TiobeRank: rank, TiobeRating: ratings[lang], TopicNum: num})
+	}
+
+	l.Trace().Msg("Recalcular puestos entre los lenguajes en común")
+	sort.Slice(corr.pairs, func(i, j int) bool { return corr.pairs[i].TiobeRank < corr.pairs[j].TiobeRank })
+	githubranks := ranks(githubvalues)
+	for i := range corr.pairs {
+		corr.pairs[i].TiobeRank = i + 1
+		corr.pairs[i].GithubRank = githubranks[corr.pairs[i].Language]
+	}
+
+	l.Trace().Msg("Calcular coeficientes")
+	corr.Spearman = corr.spearman()
+	corr.Kendall = corr.kendall()
+
+	l.Trace().Msg("EXIT")
+	return corr
+}
+
+// Correlación de Pearson entre los puestos. Los empates en github reciben el promedio de los puestos
+// que ocupan, así el coeficiente no depende del orden arbitrario entre lenguajes con la misma cantidad.
+func (corr *RankCorrelation) spearman() float64 {
+	n := float64(len(corr.pairs))
+	if n < 2 {
+		return math.NaN()
+	}
+	counts := make(map[string]float64)
+	for _, pair := range corr.pairs {
+		counts[pair.Language] = float64(pair.TopicNum)
+	}
+	githubranks := averageRanks(counts)
+
+	var xmean, ymean float64
+	for _, pair := range corr.pairs {
+		xmean += float64(pair.TiobeRank)
+		ymean += githubranks[pair.Language]
+	}
+	xmean /= n
+	ymean /= n
+	var sxy, sxx, syy float64
+	for _, pair := range corr.pairs {
+		dx := float64(pair.TiobeRank) - xmean
+		dy := githubranks[pair.Language] - ymean
+		sxy += dx * dy
+		sxx += dx * dx
+		syy += dy * dy
+	}
+	if sxx == 0 || syy == 0 {
+		return math.NaN()
+	}
+	return sxy / math.Sqrt(sxx*syy)
+}
+
+// Puestos de mayor a menor valor, con el promedio de puestos para los empates
+func averageRanks(values map[string]float64) map[string]float64 {
+	names := sortedFloatKeys(values)
+	ret := make(map[string]float64)
+	for i := 0; i < len(names); {
+		j := i
+		for j < len(names) && values[names[j]] == values[names[i]] {
+			j++
+		}
+		// Los puestos i+1 a j, su promedio es (i+1+j)/2
+		for _, name := range names[i:j] {
+			ret[name] = float64(i+1+j) / 2
+		}
+		i = j
+	}
+	return ret
+}
+
+// Tau-b sobre el puesto de tiobe y la cantidad de repositorios, considerando empates
+func (corr *RankCorrelation) kendall() float64 {
+	var concordant, discordant, tiestiobe, tiesgithub float64
+	for i := 0; i < len(corr.pairs); i++ {
+		for j := i + 1; j < len(corr.pairs); j++ {
+			a := float64(corr.pairs[j].TiobeRank - corr.pairs[i].TiobeRank)
+			b := float64(corr.pairs[i].TopicNum) - float64(corr.pairs[j].TopicNum)
+			switch {
+			case a == 0 && b == 0:
+			case a == 0:
+				tiestiobe++
+			case b == 0:
+				tiesgithub++
+			case a*b > 0:
+				concordant++
+			default:
+				discordant++
+			}
+		}
+	}
+	denominator := math.Sqrt((concordant + discordant + tiestiobe) * (concordant + discordant + tiesgithub))
+	if denominator == 0 {
+		return math.NaN()
+	}
+	return (concordant - discordant) / denominator
+}
+
+// Los n lenguajes con mayor diferencia de puesto entre tiobe y github
+func (corr *RankCorrelation) Disagreements(n int) []RankPair {
+	pairs := append([]RankPair{}, corr.pairs...)
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return abs(pairs[i].TiobeRank-pairs[i].GithubRank) > abs(pairs[j].TiobeRank-pairs[j].GithubRank)
+	})
+	if len(pairs) > n {
+		pairs = pairs[0:n]
+	}
+	return pairs
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func (corr *RankCorrelation) Graph(htmlname string) error {
+	l := corr.Logger.With().Str("method", "Graph").Logger()
+
+	l.Trace().Msg("Crear nueva gráfica")
+	scatter := charts.NewScatter()
+
+	l.Trace().Msg("Configurar opciones")
+	scatter.SetGlobalOptions(
+		charts.WithTitleOpts(opts.Title{
+			Title:    "Rating tiobe vs repositorios en Github",
+			Subtitle: fmt.Sprintf("Spearman: %.3f, Kendall: %.3f", corr.Spearman, corr.Kendall),
+		}),
+		charts.WithXAxisOpts(opts.XAxis{Name: "Rating tiobe (%)", Type: "value"}),
+		charts.WithYAxisOpts(opts.YAxis{Name: "Repositorios", Type: "value"}),
+		charts.WithTooltipOpts(opts.Tooltip{Show: true, Formatter: "{b}: {c}"}),
+		charts.WithInitializationOpts(opts.Initialization{
+			Width:  "1920px",
+			Height: "600px",
+		}),
+	)
+
+	l.Trace().Msg("Llenar datos")
+	points := make([]opts.ScatterData, 0)
+	for _, pair := range corr.pairs {
+		points = append(points, opts.ScatterData{
+			Name:       pair.Language,
+			Value:      []interface{}{pair.TiobeRating, pair.TopicNum},
+			SymbolSize: 12,
+		})
+	}
+	scatter.AddSeries("Lenguajes", points,
+		charts.WithLabelOpts(opts.Label{Show: true, Position: "right", Formatter: "{b}"}))
+
+	l.Trace().Str("html-file", htmlname).Msg("Crear archivo html")
+	f, err := os.Create(htmlname)
+	if err != nil {
+		l.Error().Err(err).Msg("No se pudo crear archivo html!")
+		return err
+	}
+	defer f.Close()
+
+	l.Trace().Msg("Guardar en archivo")
+	return scatter.Render(f)
+}
+
+func (corr *RankCorrelation) String() string {
+	if corr == nil {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Correlación tiobe vs github (%d lenguajes): Spearman %.3f, Kendall %.3f\n", len(corr.pairs), corr.Spearman, corr.Kendall))
+	sb.WriteString("Mayores diferencias de puesto:\n")
+	for _, pair := range corr.Disagreements(5) {
+		sb.WriteString(fmt.Sprintf("%30s, tiobe %3d, github %3d, diferencia %+3d\n", pair.Language, pair.TiobeRank, pair.GithubRank, pair.TiobeRank-pair.GithubRank))
+	}
+	return sb.String()
+}
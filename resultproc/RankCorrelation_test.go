@@ -0,0 +1,109 @@
+package resultproc
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestRankCorrelation(t *testing.T) {
+	tests := []struct {
+		name              string
+		tioberanks        map[string]int
+		counts            map[string]int32
+		spearman, kendall float64
+	}{
+		{
+			// Ejemplo de CI contra horas de televisión: rho = -29/165 y tau = (20 - 25)/45.
+			// El puesto de tiobe es el orden de CI de mayor a menor.
+			name:       "ejemplo clásico",
+			tioberanks: map[string]int{"a": 4, "b": 7, "c": 10, "d": 6, "e": 8, "f": 5, "g": 9, "h": 1, "i": 2, "j": 3},
+			counts:     map[string]int32{"a": 7, "b": 27, "c": 2, "d": 50, "e": 28, "f": 29, "g": 20, "h": 12, "i": 6, "j": 17},
+			spearman:   -29.0 / 165,
+			kendall:    -1.0 / 9,
+		},
+		{
+			// Puestos promedio en github 1, 2.5, 2.5, 4 y un par empatado solo en github
+			name:       "empates en github",
+			tioberanks: map[string]int{"a": 1, "b": 2, "c": 3, "d": 4},
+			counts:     map[string]int32{"a": 40, "b": 30, "c": 30, "d": 10},
+			spearman:   3 / math.Sqrt(10),
+			kendall:    5 / math.Sqrt(30),
+		},
+		{
+			name:       "mismo orden",
+			tioberanks: map[string]int{"a": 1, "b": 2, "c": 3},
+			counts:     map[string]int32{"a": 300, "b": 200, "c": 100},
+			spearman:   1,
+			kendall:    1,
+		},
+		{
+			name:       "orden inverso",
+			tioberanks: map[string]int{"a": 1, "b": 2, "c": 3},
+			counts:     map[string]int32{"a": 100, "b": 200, "c": 300},
+			spearman:   -1,
+			kendall:    -1,
+		},
+		{
+			// Sin datos de github para d, los puestos de tiobe se recalculan entre a, b y c
+			name:       "solo lenguajes en común",
+			tioberanks: map[string]int{"a": 1, "d": 2, "b": 3, "c": 4},
+			counts:     map[string]int32{"a": 300, "b": 200, "c": 100, "e": 1000},
+			spearman:   1,
+			kendall:    1,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			corr := CreateRankCorrelation(test.tioberanks, nil, test.counts, zerolog.Nop())
+			if math.Abs(corr.Spearman-test.spearman) > 1e-9 {
+				t.Errorf("spearman = %v, want %v", corr.Spearman, test.spearman)
+			}
+			if math.Abs(corr.Kendall-test.kendall) > 1e-9 {
+				t.Errorf("kendall = %v, want %v", corr.Kendall, test.kendall)
+			}
+		})
+	}
+}
+
+func TestRankCorrelationUndefined(t *testing.T) {
+	tests := []struct {
+		name       string
+		tioberanks map[string]int
+		counts     map[string]int32
+	}{
+		{"un lenguaje", map[string]int{"a": 1}, map[string]int32{"a": 10}},
+		{"todos empatados", map[string]int{"a": 1, "b": 2}, map[string]int32{"a": 10, "b": 10}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			corr := CreateRankCorrelation(test.tioberanks, nil, test.counts, zerolog.Nop())
+			if !math.IsNaN(corr.Spearman) || !math.IsNaN(corr.Kendall) {
+				t.Errorf("got spearman %v and kendall %v, want NaN", corr.Spearman, corr.Kendall)
+			}
+		})
+	}
+}
+
+func TestAverageRanks(t *testing.T) {
+	got := averageRanks(map[string]float64{"a": 5, "b": 9, "c": 5, "d": 5, "e": 1})
+	want := map[string]float64{"b": 1, "a": 3, "c": 3, "d": 3, "e": 5}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestDisagreements(t *testing.T) {
+	corr := CreateRankCorrelation(
+		map[string]int{"a": 1, "b": 2, "c": 3, "d": 4},
+		nil,
+		map[string]int32{"a": 10, "b": 40, "c": 30, "d": 20},
+		zerolog.Nop(),
+	)
+	pairs := corr.Disagreements(1)
+	if len(pairs) != 1 || pairs[0].Language != "a" || pairs[0].GithubRank != 4 {
+		t.Errorf("got %+v, want a with github rank 4", pairs)
+	}
+}
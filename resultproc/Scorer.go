@@ -0,0 +1,136 @@
+package resultproc
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"webscraping/common"
+)
+
+// Un Scorer calcula el puntaje de cada valor con respecto a todos los valores
+type Scorer interface {
+	Name() string
+	Score(values []float64) []float64
+}
+
+type MinMaxScorer struct{}
+type ZScoreScorer struct{}
+type LogScorer struct{}
+type RankPercentileScorer struct{}
+type ShareScorer struct{}
+
+var scorers = map[string]Scorer{
+	"minmax":     MinMaxScorer{},
+	"zscore":     ZScoreScorer{},
+	"log":        LogScorer{},
+	"percentile": RankPercentileScorer{},
+	"share":      ShareScorer{},
+}
+
+func GetScorer(name string) (Scorer, error) {
+	scorer, ok := scorers[strings.ToLower(name)]
+	if !ok {
+		return nil, common.NewConfigError("algoritmo_puntaje", name)
+	}
+	return scorer, nil
+}
+
+func (MinMaxScorer) Name() string { return "minmax" }
+
+// Escala lineal entre 0 (mínimo) y 100 (máximo). Si todos son iguales todos tienen 100.
+func (MinMaxScorer) Score(values []float64) []float64 {
+	return minmax(values)
+}
+
+func (ZScoreScorer) Name() string { return "zscore" }
+
+// Desviaciones estándar respecto a la media
+func (ZScoreScorer) Score(values []float64) []float64 {
+	scores := make([]float64, len(values))
+	if len(values) == 0 {
+		return scores
+	}
+	var mean, variance float64
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	stddev := math.Sqrt(variance / float64(len(values)))
+	if stddev == 0 {
+		return scores
+	}
+	for i, v := range values {
+		scores[i] = (v - mean) / stddev
+	}
+	return scores
+}
+
+func (LogScorer) Name() string { return "log" }
+
+// Como minmax pero sobre log(1+x), para que los lenguajes con muchos repositorios no aplasten al resto
+func (LogScorer) Score(values []float64) []float64 {
+	logs := make([]float64, len(values))
+	for i, v := range values {
+		logs[i] = math.Log1p(math.Max(v, 0))
+	}
+	return minmax(logs)
+}
+
+func (RankPercentileScorer) Name() string { return "percentile" }
+
+// Porcentaje de valores menores, contando los empates como la mitad
+func (RankPercentileScorer) Score(values []float64) []float64 {
+	scores := make([]float64, len(values))
+	if len(values) == 0 {
+		return scores
+	}
+	sorted := append([]float64{}, values...)
+	sort.Float64s(sorted)
+	for i, v := range values {
+		below := sort.SearchFloat64s(sorted, v)
+		equal := sort.SearchFloat64s(sorted, math.Nextafter(v, math.Inf(1))) - below
+		scores[i] = (float64(below) + float64(equal)/2) / float64(len(values)) * 100
+	}
+	return scores
+}
+
+func (ShareScorer) Name() string { return "share" }
+
+// Porcentaje del total
+func (ShareScorer) Score(values []float64) []float64 {
+	scores := make([]float64, len(values))
+	var total float64
+	for _, v := range values {
+		total += v
+	}
+	if total == 0 {
+		return scores
+	}
+	for i, v := range values {
+		scores[i] = v / total * 100
+	}
+	return scores
+}
+
+func minmax(values []float64) []float64 {
+	scores := make([]float64, len(values))
+	if len(values) == 0 {
+		return scores
+	}
+	min, max := values[0], values[0]
+	for _, v := range values {
+		min = math.Min(min, v)
+		max = math.Max(max, v)
+	}
+	for i, v := range values {
+		if max == min {
+			scores[i] = 100
+		} else {
+			scores[i] = (v - min) / (max - min) * 100
+		}
+	}
+	return scores
+}
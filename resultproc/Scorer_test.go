@@ -0,0 +1,63 @@
+package resultproc
+
+import (
+	"math"
+	"testing"
+)
+
+func TestScorers(t *testing.T) {
+	e := math.E
+	tests := []struct {
+		scorer string
+		name   string
+		values []float64
+		want   []float64
+	}{
+		{"minmax", "escala", []float64{20, 10, 30}, []float64{50, 0, 100}},
+		{"minmax", "todos iguales", []float64{5, 5, 5}, []float64{100, 100, 100}},
+		{"minmax", "uno", []float64{7}, []float64{100}},
+		{"minmax", "vacío", []float64{}, []float64{}},
+		{"zscore", "escala", []float64{2, 4, 4, 4, 5, 5, 7, 9}, []float64{-1.5, -0.5, -0.5, -0.5, 0, 0, 1, 2}},
+		{"zscore", "desviación cero", []float64{3, 3}, []float64{0, 0}},
+		{"zscore", "vacío", []float64{}, []float64{}},
+		{"log", "escala", []float64{0, e - 1, e*e - 1}, []float64{0, 50, 100}},
+		{"log", "negativos como cero", []float64{-5, 0}, []float64{100, 100}},
+		{"log", "vacío", []float64{}, []float64{}},
+		{"percentile", "empates como la mitad", []float64{20, 10, 30, 20}, []float64{50, 12.5, 87.5, 50}},
+		{"percentile", "todos iguales", []float64{7, 7, 7}, []float64{50, 50, 50}},
+		{"percentile", "vacío", []float64{}, []float64{}},
+		{"share", "escala", []float64{1, 3}, []float64{25, 75}},
+		{"share", "total cero", []float64{0, 0}, []float64{0, 0}},
+		{"share", "vacío", []float64{}, []float64{}},
+	}
+	for _, test := range tests {
+		t.Run(test.scorer+" "+test.name, func(t *testing.T) {
+			scorer, err := GetScorer(test.scorer)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if scorer.Name() != test.scorer {
+				t.Errorf("name = %v, want %v", scorer.Name(), test.scorer)
+			}
+			got := scorer.Score(test.values)
+			if len(got) != len(test.want) {
+				t.Fatalf("got %v, want %v", got, test.want)
+			}
+			for i := range got {
+				if math.Abs(got[i]-test.want[i]) > 1e-9 {
+					t.Errorf("got %v, want %v", got, test.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestGetScorer(t *testing.T) {
+	if scorer, err := GetScorer("ZScore"); err != nil || scorer.Name() != "zscore" {
+		t.Errorf("got %v, %v, want zscore", scorer, err)
+	}
+	if _, err := GetScorer("median"); err == nil {
+		t.Error("expected error for unknown scorer")
+	}
+}
@@ -0,0 +1,193 @@
+package resultproc
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/opts"
+	"github.com/rs/zerolog"
+)
+
+type TagLanguageTable struct {
+	Logger    zerolog.Logger
+	Interest  string
+	Sort      string
+	counts    map[string]map[string]int
+	tags      []string
+	languages []string
+}
+
+func CreateTagLanguageTable(table map[string]map[string]int, logger zerolog.Logger) TagLanguageTable {
+	var tab TagLanguageTable
+	l := logger.With().Str("function", "CreateTagLanguageTable").Logger()
+
+	l.Trace().Msg("Crear logger")
+	tab.Logger = logger.With().Str("struct", "TagLanguageTable").Logger()
+	tab.counts = table
+
+	l.Trace().Msg("Calcular totales por tag y por lenguaje")
+	tagtotals := make(map[string]int)
+	langtotals := make(map[string]int)
+	for tag, langs := range table {
+		for lang, num := range langs {
+			tagtotals[tag] += num
+			langtotals[lang] += num
+		}
+	}
+	tab.tags = sortedKeys(tagtotals)
+	tab.languages = sortedKeys(langtotals)
+
+	l.Trace().Msg("EXIT")
+	return tab
+}
+
+// Claves ordenadas de mayor a menor total, y alfabéticamente en empate
+func sortedKeys(totals map[string]int) []string {
+	keys := make([]string, 0, len(totals))
+	for key := range totals {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if totals[keys[i]] != totals[keys[j]] {
+			return totals[keys[i]] > totals[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+func (tab *TagLanguageTable) Save(filename string) error {
+	l := tab.Logger.With().Str("method", "Save").Logger()
+
+	l.Trace().Msg("Abriendo archivo")
+	file, err := os.Create(filename)
+	if err != nil {
+		l.Error().Err(err).Msg("No se pudo abrir ni crear archivo!")
+		return err
+	}
+	defer file.Close()
+
+	l.Trace().Msg("Guardando tabla en csv")
+	writer := csv.NewWriter(file)
+	header := append([]string{"tag"}, tab.languages...)
+	if err := writer.Write(append(header, "total")); err != nil {
+		l.Error().Err(err).Msg("No se pudo escribir en archivo")
+		return err
+	}
+	for _, tag := range tab.tags {
+		row := []string{tag}
+		total := 0
+		for _, lang := range tab.languages {
+			num := tab.counts[tag][lang]
+			total += num
+			row = append(row, strconv.Itoa(num))
+		}
+		if err := writer.Write(append(row, strconv.Itoa(total))); err != nil {
+			l.Error().Err(err).Msg("No se pudo escribir en archivo")
+			return err
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		l.Error().Err(err).Msg("No se pudo escribir en archivo")
+		return err
+	}
+	l.Trace().Msg("EXIT")
+	return nil
+}
+
+func (tab *TagLanguageTable) Graph(htmlname string) error {
+	l := tab.Logger.With().Str("method", "Graph").Logger()
+
+	l.Trace().Msg("Crear nuevo mapa de calor")
+	heatmap := charts.NewHeatMap()
+
+	tags := tab.tags
+	if len(tags) > 20 {
+		tags = tags[0:20]
+	}
+	langs := tab.languages
+	if len(langs) > 10 {
+		langs = langs[0:10]
+	}
+
+	l.Trace().Msg("Llenar datos")
+	max := 0
+	data := make([]opts.HeatMapData, 0)
+	for x, tag := range tags {
+		for y, lang := range langs {
+			num := tab.counts[tag][lang]
+			if num > max {
+				max = num
+			}
+			data = append(data, opts.HeatMapData{Value: [3]interface{}{x, y, num}})
+		}
+	}
+
+	l.Trace().Msg("Configurar opciones")
+	heatmap.SetGlobalOptions(
+		charts.WithTitleOpts(opts.Title{
+			Title:    "Top 20 tags por lenguaje",
+			Subtitle: fmt.Sprintf("tag: %v, orden: %v", tab.Interest, tab.Sort),
+		}),
+		charts.WithXAxisOpts(opts.XAxis{
+			Type: "category",
+			Data: tags,
+		}),
+		charts.WithYAxisOpts(opts.YAxis{
+			Type: "category",
+			Data: langs,
+		}),
+		charts.WithVisualMapOpts(opts.VisualMap{
+			Calculable: true,
+			Min:        0,
+			Max:        float32(max),
+		}),
+		charts.WithInitializationOpts(opts.Initialization{
+			Width:  "1920px",
+			Height: "600px",
+		}),
+	)
+	heatmap.SetXAxis(tags).
+		AddSeries("Repositorios", data)
+
+	l.Trace().Str("html-file", htmlname).Msg("Crear archivo html")
+	f, err := os.Create(htmlname)
+	if err != nil {
+		l.Error().Err(err).Msg("No se pudo crear archivo html!")
+		return err
+	}
+	defer f.Close()
+
+	l.Trace().Msg("Guardar en archivo")
+	return heatmap.Render(f)
+}
+
+func (tab *TagLanguageTable) String() string {
+	if tab == nil {
+		return ""
+	}
+	var sb strings.Builder
+	tags := tab.tags
+	if len(tags) > 20 {
+		tags = tags[0:20]
+	}
+	for _, tag := range tags {
+		var langs []string
+		for _, lang := range tab.languages {
+			if num := tab.counts[tag][lang]; num > 0 {
+				langs = append(langs, fmt.Sprintf("%v (%d)", lang, num))
+			}
+			if len(langs) == 3 {
+				break
+			}
+		}
+		sb.WriteString(fmt.Sprintf("%-30s: %v\n", tag, strings.Join(langs, ", ")))
+	}
+	return sb.String()
+}
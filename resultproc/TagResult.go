@@ -2,7 +2,6 @@ package resultproc
 
 import (
 	"fmt"
-	"os"
 
 	"github.com/rs/zerolog"
 )
@@ -25,16 +24,3 @@ func (res *TagResult) String() string {
 	}
 	return fmt.Sprintf("%-30s: %d", res.Tag, res.Num)
 }
-
-func (res *TagResult) Save(file *os.File) error {
-	l := res.Logger.With().Str("method", "Save").Str("lang", res.Tag).Logger()
-
-	l.Trace().Msg("Intentando guardar resultado")
-	_, err := file.WriteString(fmt.Sprintf("%v\n", res.Tag))
-	if err != nil {
-		l.Error().Err(err).Msg("No se pudo escribir en archivo")
-		return err
-	}
-	l.Trace().Msg("EXIT")
-	return nil
-}
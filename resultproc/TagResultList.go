@@ -1,18 +1,21 @@
 package resultproc
 
 import (
-	"os"
+	"fmt"
 	"sort"
 	"strings"
+	"webscraping/export"
+	"webscraping/staticchart"
+	"webscraping/terminal"
 
-	"github.com/go-echarts/go-echarts/v2/charts"
-	"github.com/go-echarts/go-echarts/v2/opts"
 	"github.com/rs/zerolog"
 )
 
 type TagResultList struct {
-	Logger  zerolog.Logger
-	results []TagResult
+	Logger   zerolog.Logger
+	Interest string
+	Sort     string
+	results  []TagResult
 }
 
 func CreateTagResultList(results map[string]int, logger zerolog.Logger) TagResultList {
@@ -35,50 +38,35 @@ func CreateTagResultList(results map[string]int, logger zerolog.Logger) TagResul
 	return resl
 }
 
-func (resl *TagResultList) Graph(htmlname string) error {
+func (resl *TagResultList) Graph(htmlname string, config ChartConfig) error {
 	l := resl.Logger.With().Str("method", "Graph").Logger()
 
-	l.Trace().Msg("Crear nueva gráfica")
-	bar := charts.NewBar()
-
-	l.Trace().Msg("Configurar opciones")
-	bar.SetGlobalOptions(
-		charts.WithTitleOpts(opts.Title{
-			Title: "Top 20 tags",
-		}),
-		charts.WithDataZoomOpts(opts.DataZoom{
-			Type:  "slider",
-			Start: 0,
-			End:   100,
-		}),
-		charts.WithInitializationOpts(opts.Initialization{
-			Width:  "1920px",
-			Height: "600px",
-		}),
-	)
-
-	l.Trace().Msg("Llenar datos")
-	taglist := resl.getTagList()
-	if len(taglist) > 20 {
-		taglist = taglist[0:20]
-	}
-	barlist := resl.getBars()
-	if len(barlist) > 20 {
-		barlist = barlist[0:20]
-	}
-	bar.SetXAxis(taglist).
-		AddSeries("Tags", barlist)
-
-	l.Trace().Str("html-file", htmlname).Msg("Crear archivo html")
-	f, err := os.Create(htmlname)
-	if err != nil {
-		l.Error().Err(err).Msg("No se pudo crear archivo html!")
-		return err
+	l.Trace().Msg("Crear gráfica")
+	return buildChart(htmlname, config, resl.getChartItems(), nil, resl.title(config), resl.label(), l)
+}
+
+// Gráfica sin javascript, en los formatos de static. Retorna los archivos creados.
+func (resl *TagResultList) StaticGraph(base string, config ChartConfig, static staticchart.Config) ([]string, error) {
+	l := resl.Logger.With().Str("method", "StaticGraph").Logger()
+
+	l.Trace().Msg("Crear gráfica estática")
+	return buildStatic(base, config, static, resl.getChartItems(), resl.title(config), resl.label(), l)
+}
+
+// Gráfica sin javascript, para incluir en otros reportes
+func (resl *TagResultList) StaticChart(config ChartConfig) (staticchart.Chart, error) {
+	return staticBar(config, resl.getChartItems(), resl.title(config), resl.label(), resl.Logger)
+}
+
+func (resl *TagResultList) title(config ChartConfig) string {
+	if config.Top <= 0 {
+		return "Tags"
 	}
+	return fmt.Sprintf("Top %d tags", config.Top)
+}
 
-	l.Trace().Msg("Guardar en archivo")
-	bar.Render(f)
-	return nil
+func (resl *TagResultList) Results() []TagResult {
+	return resl.results
 }
 
 func (resl *TagResultList) TagSort() {
@@ -88,41 +76,50 @@ func (resl *TagResultList) TagSort() {
 	sort.Sort(sort.Reverse(TagSort(resl.results)))
 }
 
-func (resl *TagResultList) getTagList() []string {
-	l := resl.Logger.With().Str("method", "getTagList").Logger()
+// Como puntaje se usa la participación de cada tag sobre el total de menciones
+func (resl *TagResultList) getChartItems() []ChartItem {
+	l := resl.Logger.With().Str("method", "getChartItems").Logger()
 
-	l.Trace().Msg("Obtener slice de tags")
-	var tags []string
-	for _, res := range resl.results {
-		tags = append(tags, res.Tag)
+	l.Trace().Msg("Obtener slice de elementos a graficar")
+	shares := resl.shares()
+	items := make([]ChartItem, 0, len(resl.results))
+	for i, res := range resl.results {
+		items = append(items, ChartItem{Name: res.Tag, Count: float64(res.Num), Score: shares[i]})
 	}
-
-	l.Trace().Msg("Retornar slice de tags")
-	return tags
+	return items
 }
-func (resl *TagResultList) getValueList() []int {
-	l := resl.Logger.With().Str("method", "getValueList").Logger()
 
-	l.Trace().Msg("Obtener slice de valores")
-	var tagnums []int
+func (resl *TagResultList) shares() []float64 {
+	var total int
 	for _, res := range resl.results {
-		tagnums = append(tagnums, res.Num)
+		total += res.Num
 	}
-
-	l.Trace().Msg("Retornar slice de valores int32")
-	return tagnums
+	shares := make([]float64, len(resl.results))
+	for i, res := range resl.results {
+		if total > 0 {
+			shares[i] = float64(res.Num) / float64(total) * 100
+		}
+	}
+	return shares
 }
-func (resl *TagResultList) getBars() []opts.BarData {
-	l := resl.Logger.With().Str("method", "getBars").Logger()
 
-	l.Trace().Msg("Obtener slice de barras")
-	bars := make([]opts.BarData, 0)
-	for _, num := range resl.getValueList() {
-		bars = append(bars, opts.BarData{Value: num})
+// Tabla para la terminal, con barras según las menciones
+func (resl *TagResultList) TerminalTable() *terminal.Table {
+	table := terminal.Table{
+		Title: fmt.Sprintf("Tags (%v)", resl.label()),
+		Columns: []terminal.Column{
+			{Name: "#", Align: terminal.AlignRight},
+			{Name: "Tag", Align: terminal.AlignLeft},
+			{Name: "%", Align: terminal.AlignRight},
+			{Name: "Menciones", Align: terminal.AlignRight},
+		},
 	}
-
-	l.Trace().Msg("Retornar slice de baras")
-	return bars
+	shares := resl.shares()
+	for i, res := range resl.results {
+		table.Rows = append(table.Rows, []string{fmt.Sprint(i + 1), res.Tag, terminal.Float(shares[i], 2), terminal.Count(int64(res.Num))})
+		table.Bars = append(table.Bars, float64(res.Num))
+	}
+	return &table
 }
 
 func (resl *TagResultList) String() string {
@@ -137,24 +134,24 @@ func (resl *TagResultList) String() string {
 	return sb.String()
 }
 
-func (resl *TagResultList) Save(filename string) error {
-	l := resl.Logger.With().Str("method", "Save").Logger()
+// Tabla para exportar, con la participación de cada tag sobre el total de menciones como puntaje
+func (resl *TagResultList) Table(meta export.Metadata) *export.Table {
+	l := resl.Logger.With().Str("method", "Table").Logger()
 
-	l.Trace().Msg("Abriendo archivo")
-	file, err := os.Create(filename)
-	if err != nil {
-		l.Error().Err(err).Msg("No se pudo abrir ni crear archivo!")
-		return err
+	l.Trace().Msg("Armando tabla de resultados")
+	meta.Interest = resl.Interest
+	meta.Sort = resl.Sort
+	table := export.Table{
+		Metadata: meta,
+		Columns:  []string{"rank", "tag", "share", "count"},
 	}
-	defer file.Close()
-
-	l.Trace().Msg("Guardando resultando")
-	for _, res := range resl.results {
-		err = res.Save(file)
-		if err != nil {
-			l.Error().Err(err).Msg("No se pudo guardar resultado!")
-			return err
-		}
+	shares := resl.shares()
+	for i, res := range resl.results {
+		table.Rows = append(table.Rows, []interface{}{i + 1, res.Tag, shares[i], res.Num})
 	}
-	return nil
+	return &table
+}
+
+func (resl *TagResultList) label() string {
+	return fmt.Sprintf("tag: %v, orden: %v", resl.Interest, resl.Sort)
 }
@@ -0,0 +1,117 @@
+package resultproc
+
+import (
+	"fmt"
+	"os"
+	"webscraping/common"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/opts"
+)
+
+// Cuándo generar la nube de tags respecto a la gráfica de barras
+const (
+	WordCloudOff       = "off"
+	WordCloudAlongside = "alongside"
+	WordCloudOnly      = "only"
+)
+
+// Formas soportadas por echarts-wordcloud
+var wordCloudShapes = map[string]bool{
+	"circle":           true,
+	"cardioid":         true,
+	"diamond":          true,
+	"triangle-forward": true,
+	"triangle":         true,
+	"pentagon":         true,
+	"star":             true,
+}
+
+type WordCloudConfig struct {
+	Mode string `json:"mode" yaml:"mode"`
+	// Archivo de la nube con mode alongside, con only se usa el de la gráfica principal
+	HtmlFile string `json:"html_file" yaml:"html_file"`
+	Shape    string `json:"shape" yaml:"shape"`
+	MinSize  int    `json:"min_size" yaml:"min_size"`
+	MaxSize  int    `json:"max_size" yaml:"max_size"`
+	Top      int    `json:"top" yaml:"top"`
+}
+
+func GetDefaultWordCloudConfig() WordCloudConfig {
+	return WordCloudConfig{
+		Mode:     WordCloudAlongside,
+		HtmlFile: "nube_tags.html",
+		Shape:    "circle",
+		MinSize:  14,
+		MaxSize:  80,
+		Top:      100,
+	}
+}
+
+// Revisa el modo, la forma y el rango de tamaños
+func (config *WordCloudConfig) Validate() error {
+	if config.Mode != WordCloudOff && config.Mode != WordCloudAlongside && config.Mode != WordCloudOnly {
+		return common.NewConfigError("wordcloud.mode", config.Mode)
+	}
+	if !wordCloudShapes[config.Shape] {
+		return common.NewConfigError("wordcloud.shape", config.Shape)
+	}
+	if config.MinSize <= 0 || config.MaxSize < config.MinSize {
+		return common.NewConfigError("wordcloud.min_size/max_size", fmt.Sprintf("%d-%d", config.MinSize, config.MaxSize))
+	}
+	return nil
+}
+
+func (resl *TagResultList) WordCloud(htmlname string, config WordCloudConfig) error {
+	l := resl.Logger.With().Str("method", "WordCloud").Logger()
+
+	if !wordCloudShapes[config.Shape] {
+		l.Error().Str("shape", config.Shape).Msg("Forma no soportada! Use circle, cardioid, diamond, triangle-forward, triangle, pentagon o star")
+		return common.NewConfigError("wordcloud.shape", config.Shape)
+	}
+	if config.MinSize <= 0 || config.MaxSize < config.MinSize {
+		l.Error().Int("min", config.MinSize).Int("max", config.MaxSize).Msg("Rango de tamaños inválido")
+		return common.NewConfigError("wordcloud.min_size/max_size", fmt.Sprintf("%d-%d", config.MinSize, config.MaxSize))
+	}
+
+	l.Trace().Msg("Crear nueva nube")
+	wc := charts.NewWordCloud()
+
+	l.Trace().Msg("Configurar opciones")
+	wc.SetGlobalOptions(
+		charts.WithTitleOpts(opts.Title{
+			Title:    "Nube de tags",
+			Subtitle: resl.label(),
+		}),
+		charts.WithTooltipOpts(opts.Tooltip{Show: true}),
+		charts.WithInitializationOpts(opts.Initialization{
+			Width:  "1920px",
+			Height: "900px",
+		}),
+	)
+
+	l.Trace().Msg("Llenar datos")
+	results := resl.results
+	if config.Top > 0 && len(results) > config.Top {
+		results = results[0:config.Top]
+	}
+	words := make([]opts.WordCloudData, 0, len(results))
+	for _, res := range results {
+		words = append(words, opts.WordCloudData{Name: res.Tag, Value: res.Num})
+	}
+	wc.AddSeries("Tags", words, charts.WithWorldCloudChartOpts(opts.WordCloudChart{
+		Shape:     config.Shape,
+		SizeRange: []float32{float32(config.MinSize), float32(config.MaxSize)},
+	}))
+
+	l.Trace().Str("html-file", htmlname).Msg("Crear archivo html")
+	f, err := os.Create(htmlname)
+	if err != nil {
+		l.Error().Err(err).Msg("No se pudo crear archivo html!")
+		return err
+	}
+	defer f.Close()
+
+	l.Trace().Msg("Guardar en archivo")
+	return wc.Render(f)
+}
@@ -0,0 +1,130 @@
+package scraping
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	neturl "net/url"
+	"strconv"
+	"strings"
+	"time"
+	"webscraping/common"
+	"webscraping/metrics"
+)
+
+// Resultado de una consulta HTTP, con sus reintentos
+type FetchRecord struct {
+	URL      string        `json:"url"`
+	Status   int           `json:"status"`
+	Attempts int           `json:"attempts"`
+	Started  time.Time     `json:"started"`
+	Duration time.Duration `json:"duration"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// Resumen de todas las consultas hechas por el scraper
+type ScrapeReport struct {
+	Requests int           `json:"requests"`
+	Retries  int           `json:"retries"`
+	Failures int           `json:"failures"`
+	Duration time.Duration `json:"duration"`
+	Records  []FetchRecord `json:"records"`
+}
+
+// Hace la consulta reintentando con RetryDelaysMs mientras github o tiobe retornen un código de error.
+// Los errores de conexión no se reintentan.
+func (sc *Scraper) fetch(url string) ([]byte, error) {
+	l := sc.Logger.With().Str("method", "fetch").Str("url", url).Logger()
+
+	record := FetchRecord{URL: url, Started: time.Now()}
+	content, err := sc.fetchRetry(url, &record)
+	record.Duration = time.Since(record.Started)
+	if err != nil {
+		record.Error = err.Error()
+	}
+	l.Trace().Int("status", record.Status).Int("intentos", record.Attempts).Dur("duracion", record.Duration).Msg("Consulta terminada")
+
+	sc.mutex.Lock()
+	sc.records = append(sc.records, record)
+	sc.mutex.Unlock()
+	return content, err
+}
+
+func (sc *Scraper) fetchRetry(url string, record *FetchRecord) ([]byte, error) {
+	l := sc.Logger.With().Str("method", "fetchRetry").Str("url", url).Logger()
+
+	host := url
+	if parsed, err := neturl.Parse(url); err == nil {
+		host = parsed.Host
+	}
+	delays := append([]int{0}, sc.Config.RetryDelaysMs...)
+	for i, delay := range delays {
+		if i > 0 {
+			l.Warn().Int("Código error", record.Status).Int("Tiempo espera", delay).Msg("La página retorno un error. Reintentando después de tiempo espera...")
+			sc.Metrics.Add(metrics.RetriesTotal, metrics.Labels{"host": host}, 1)
+			time.Sleep(time.Millisecond * time.Duration(delay))
+		}
+		record.Attempts++
+		start := time.Now()
+		response, err := http.Get(url)
+		sc.Metrics.Observe(metrics.RequestDuration, metrics.Labels{"host": host}, time.Since(start).Seconds())
+		if err != nil {
+			sc.Metrics.Add(metrics.RequestsTotal, metrics.Labels{"host": host, "status": "error"}, 1)
+			return nil, err
+		}
+		sc.Metrics.Add(metrics.RequestsTotal, metrics.Labels{"host": host, "status": strconv.Itoa(response.StatusCode)}, 1)
+		record.Status = response.StatusCode
+		if response.StatusCode != http.StatusOK {
+			response.Body.Close()
+			continue
+		}
+		l.Trace().Msg("Leer todo el contenido a cadena")
+		content, err := io.ReadAll(response.Body)
+		response.Body.Close()
+		return content, err
+	}
+	return nil, common.NewStatusCodeError(record.Status)
+}
+
+// Cuenta una página o elemento que no se pudo leer
+func (sc *Scraper) parseFailure(source string) {
+	sc.Metrics.Add(metrics.ParseFailuresTotal, metrics.Labels{"source": source}, 1)
+}
+
+// Resumen de las consultas hechas desde que se creó el scraper
+func (sc *Scraper) Report() ScrapeReport {
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+
+	report := ScrapeReport{Records: append([]FetchRecord{}, sc.records...)}
+	var first, last time.Time
+	for _, record := range sc.records {
+		report.Requests += record.Attempts
+		report.Retries += record.Attempts - 1
+		if record.Error != "" {
+			report.Failures++
+		}
+		if first.IsZero() || record.Started.Before(first) {
+			first = record.Started
+		}
+		if end := record.Started.Add(record.Duration); end.After(last) {
+			last = end
+		}
+	}
+	report.Duration = last.Sub(first)
+	return report
+}
+
+func (rep *ScrapeReport) String() string {
+	if rep == nil {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Consultas: %d, reintentos: %d, fallidas: %d, duración: %v\n", rep.Requests, rep.Retries, rep.Failures, rep.Duration.Round(time.Millisecond)))
+	for _, record := range rep.Records {
+		if record.Error != "" {
+			sb.WriteString(fmt.Sprintf("    %v: %v\n", record.URL, record.Error))
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,395 @@
+package scraping
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"webscraping/common"
+)
+
+// Razones por las que se dejó de paginar en ScrapeInterest
+const (
+	StopLastPage  = "ultima_pagina"
+	StopHorizon   = "horizonte"
+	StopPageLimit = "limite_paginas"
+	StopErrors    = "errores"
+)
+
+type interestSort struct {
+	// Valor del parámetro s en la URL de github
+	query string
+	// Texto que antecede a la fecha usada para el filtro de recientes
+	label string
+	// La lista está ordenada por esa fecha, por lo que se puede cortar en el horizonte
+	timeordered bool
+}
+
+var interestSorts = map[string]interestSort{
+	"updated": {query: "updated", label: "Updated", timeordered: true},
+	// github muestra la fecha de actualización aunque se ordene por creación, así que la fecha que se
+	// encuentra no siempre es la del orden y no se puede cortar en el horizonte
+	"created": {query: "created", label: "Created", timeordered: false},
+	"stars":   {query: "stars", label: "Updated", timeordered: false},
+	"forks":   {query: "forks", label: "Updated", timeordered: false},
+}
+
+type InterestResult struct {
+	Topics map[string]int
+	// Tabla de contingencia tag -> lenguaje principal -> repositorios
+	Languages map[string]map[string]int
+	Report    InterestReport
+}
+
+type InterestReport struct {
+	Interest           string
+	Sort               string
+	PagesFetched       int
+	PagesFailed        int
+	Repositories       int
+	RecentRepositories int
+	Duplicates         int
+	ShiftGaps          int
+	StopReason         string
+	StopPage           int
+	Merged             []TagChange
+	Dropped            []TagChange
+}
+
+type interestRepo struct {
+	Name      string
+	Language  string
+	Timestamp time.Time
+	Tags      []string
+}
+
+type interestPage struct {
+	page  int
+	repos []interestRepo
+	err   error
+}
+
+type interestRegexps struct {
+	article   *regexp.Regexp
+	title     *regexp.Regexp
+	reponame  *regexp.Regexp
+	language  *regexp.Regexp
+	timehtml  *regexp.Regexp
+	timestamp *regexp.Regexp
+	sort      interestSort
+	tag       *regexp.Regexp
+	tagbeg    *regexp.Regexp
+	tagfin    *regexp.Regexp
+}
+
+func newInterestRegexps(order interestSort) interestRegexps {
+	return interestRegexps{
+		article:   regexp.MustCompile(`<article.*>(.|\n)*?</article>`),
+		title:     regexp.MustCompile(`<h3(.|\n)*?</h3>`),
+		reponame:  regexp.MustCompile(`href="/([\w.-]+/[\w.-]+)"`),
+		language:  regexp.MustCompile(`itemprop="programmingLanguage"[^>]*>([^<]+)<`),
+		timehtml:  regexp.MustCompile(`<relative-time[^>]*>(.|\n)*?</relative-time>`),
+		sort:      order,
+		timestamp: regexp.MustCompile(`\d\d\d\d-\d\d-\d\dT\d\d:\d\d:\d\dZ`),
+		tag:       regexp.MustCompile(`<a.*topic-tag topic-tag.*>(.|\n)*?</a>`),
+		tagbeg:    regexp.MustCompile(`<a.*topic-tag topic-tag.*>`),
+		tagfin:    regexp.MustCompile(`</a>`),
+	}
+}
+
+func (rep *InterestReport) String() string {
+	if rep == nil {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Tag: %v, orden: %v, páginas leídas: %d, páginas con error: %d, repositorios: %d, recientes: %d, duplicados descartados: %d, posibles saltos: %d, fin: %v (página %d)\n",
+		rep.Interest, rep.Sort, rep.PagesFetched, rep.PagesFailed, rep.Repositories, rep.RecentRepositories, rep.Duplicates, rep.ShiftGaps, rep.StopReason, rep.StopPage))
+	if len(rep.Merged) > 0 {
+		sb.WriteString("Tags unidos:\n")
+		for _, change := range rep.Merged {
+			sb.WriteString(fmt.Sprintf("    %v\n", change.String()))
+		}
+	}
+	if len(rep.Dropped) > 0 {
+		sb.WriteString("Tags descartados:\n")
+		for _, change := range rep.Dropped {
+			sb.WriteString(fmt.Sprintf("    %v\n", change.String()))
+		}
+	}
+	return sb.String()
+}
+
+func (sc *Scraper) ScrapeInterest() (*InterestResult, error) {
+	l := sc.Logger.With().Str("method", "ScrapeInterest").Logger()
+
+	l.Trace().Msgf("Preparando para scraping de github: %v", sc.Config.Interest)
+	sortname := strings.ToLower(sc.Config.InterestSort)
+	order, ok := interestSorts[sortname]
+	if !ok {
+		err := common.NewConfigError("interest_sort", sc.Config.InterestSort)
+		l.Error().Err(err).Msg("Orden no soportado! Use updated, stars, forks o created")
+		return &InterestResult{Topics: make(map[string]int), Languages: make(map[string]map[string]int)}, err
+	}
+	norm, err := NewTagNormalizer(sc.Config.Tags, sc.Logger)
+	if err != nil {
+		return &InterestResult{Topics: make(map[string]int), Languages: make(map[string]map[string]int)}, err
+	}
+	result := InterestResult{
+		Topics:    make(map[string]int),
+		Languages: make(map[string]map[string]int),
+		Report:    InterestReport{Interest: sc.Config.Interest, Sort: sortname},
+	}
+
+	l.Trace().Msgf("Compilando expresiónes regulares...")
+	re := newInterestRegexps(order)
+
+	l.Trace().Msgf("Leer tiempo referencia")
+	now := time.Now()
+	// interest_max_age_days: 0 cuenta todos los repositorios, sin horizonte
+	horizon := time.Duration(sc.Config.InterestMaxAgeDays*24) * time.Hour
+
+	maxpages := sc.Config.MaxPagesInterest
+	batch := maxpages
+	if sc.Config.AdaptivePaging {
+		l.Trace().Msg("Paginación adaptativa, leyendo de a MaxParallel páginas")
+		maxpages = sc.Config.AdaptiveMaxPages
+		batch = sc.Config.MaxParallel
+	}
+	if sc.Config.SequentialPaging {
+		l.Trace().Msg("Paginación secuencial, leyendo de a una página")
+		batch = 1
+	}
+	if batch < 1 {
+		batch = 1
+	}
+	seen := make(map[string]bool)
+
+	var lastError error
+	// Debemos empezar en pagina 1, porque si no github en pagina 0 y 1 retorna el mismo contenido
+	for first := 1; first <= maxpages && result.Report.StopReason == ""; first += batch {
+		last := first + batch - 1
+		if last > maxpages {
+			last = maxpages
+		}
+		l.Debug().Int("desde", first).Int("hasta", last).Msg("Leyendo páginas")
+		pages := sc.scrapeInterestPages(first, last, &re)
+
+		failed := 0
+		for _, page := range pages {
+			if page.err != nil {
+				lastError = page.err
+				failed++
+				result.Report.PagesFailed++
+				continue
+			}
+			result.Report.PagesFetched++
+			if len(page.repos) == 0 {
+				l.Debug().Int("página", page.page).Msg("Página sin repositorios, última página alcanzada")
+				result.Report.StopReason = StopLastPage
+				result.Report.StopPage = page.page
+				break
+			}
+
+			recent, older := 0, 0
+			for _, repo := range page.repos {
+				if repo.Name != "" && seen[repo.Name] {
+					l.Debug().Str("repositorio", repo.Name).Int("página", page.page).Msg("Repositorio repetido, la lista se desplazó. Descartando...")
+					result.Report.Duplicates++
+					continue
+				}
+				seen[repo.Name] = true
+				result.Report.Repositories++
+				l.Trace().Msg("Calculando diferencia en tiempo")
+				if horizon > 0 && now.Sub(repo.Timestamp) > horizon {
+					l.Trace().Msgf("Este artículo es de hace más de %d días, saltando...", sc.Config.InterestMaxAgeDays)
+					older++
+					continue
+				}
+				recent++
+				result.addRepo(repo, norm)
+			}
+			result.Report.RecentRepositories += recent
+
+			if sc.Config.AdaptivePaging && order.timeordered && recent == 0 && older > 0 {
+				l.Debug().Int("página", page.page).Msg("Todos los repositorios son anteriores a la ventana, deteniendo")
+				result.Report.StopReason = StopHorizon
+				result.Report.StopPage = page.page
+				break
+			}
+		}
+		if result.Report.StopReason == "" && failed == len(pages) {
+			l.Error().Int("desde", first).Int("hasta", last).Msg("No se pudo leer ninguna página del lote, deteniendo")
+			result.Report.StopReason = StopErrors
+			result.Report.StopPage = last
+		}
+	}
+	if result.Report.StopReason == "" {
+		result.Report.StopReason = StopPageLimit
+		result.Report.StopPage = maxpages
+	}
+
+	if sc.Config.SequentialPaging && sortname == "updated" && result.Report.PagesFetched > 1 {
+		sc.checkShiftGaps(&result, seen, now, &re, norm)
+	}
+	result.Report.Merged = norm.Merged()
+	result.Report.Dropped = norm.Dropped()
+
+	l.Info().Str("razon", result.Report.StopReason).Int("pagina", result.Report.StopPage).Msg("Paginación terminada")
+	return &result, lastError
+}
+
+func (res *InterestResult) addRepo(repo interestRepo, norm *TagNormalizer) {
+	counted := make(map[string]bool)
+	for _, tag := range repo.Tags {
+		tag, ok := norm.Normalize(tag)
+		if !ok || counted[tag] {
+			continue
+		}
+		counted[tag] = true
+		res.Topics[tag] = res.Topics[tag] + 1
+		if repo.Language == "" {
+			continue
+		}
+		if res.Languages[tag] == nil {
+			res.Languages[tag] = make(map[string]int)
+		}
+		res.Languages[tag][repo.Language] = res.Languages[tag][repo.Language] + 1
+	}
+}
+
+// Un repositorio actualizado durante el scraping que no habíamos visto estaba en una
+// página todavía no leída, y al subir a la primera página desplazó la lista hacia arriba.
+func (sc *Scraper) checkShiftGaps(result *InterestResult, seen map[string]bool, start time.Time, re *interestRegexps, norm *TagNormalizer) {
+	l := sc.Logger.With().Str("method", "checkShiftGaps").Logger()
+
+	l.Trace().Msg("Volviendo a leer la primera página para detectar desplazamientos")
+	repos, err := sc.scrapeInterestPage(1, re)
+	if err != nil {
+		l.Warn().Err(err).Msg("No se pudo verificar desplazamientos")
+		return
+	}
+	for _, repo := range repos {
+		if repo.Name == "" || seen[repo.Name] || !repo.Timestamp.After(start) {
+			continue
+		}
+		l.Debug().Str("repositorio", repo.Name).Msg("Repositorio actualizado durante el scraping, posible salto")
+		seen[repo.Name] = true
+		result.Report.ShiftGaps++
+		result.Report.Repositories++
+		result.Report.RecentRepositories++
+		result.addRepo(repo, norm)
+	}
+}
+
+func (sc *Scraper) scrapeInterestPages(first, last int, re *interestRegexps) []interestPage {
+	pages := make([]interestPage, last-first+1)
+
+	var wg sync.WaitGroup
+	maxchannel := make(chan struct{}, sc.Config.MaxParallel)
+	for i := first; i <= last; i++ {
+		wg.Add(1)
+		page := i
+
+		go func() {
+			defer wg.Done()
+			// Contar, bloquea si se estan ejecutando ya MaxParallel rutinas
+			maxchannel <- struct{}{}
+			repos, err := sc.scrapeInterestPage(page, re)
+			pages[page-first] = interestPage{page: page, repos: repos, err: err}
+			<-maxchannel
+		}()
+	}
+	wg.Wait()
+	return pages
+}
+
+func (sc *Scraper) scrapeInterestPage(page int, re *interestRegexps) ([]interestRepo, error) {
+	l := sc.Logger.With().Str("method", "scrapeInterestPage").Int("page", page).Logger()
+
+	url, err := sc.interestURL(page, re.sort)
+	if err != nil {
+		l.Error().Err(err).Msg("URL de github_interest_format inválida!")
+		return nil, err
+	}
+	l.Trace().Str("url", url).Msgf("Haciendo consulta HTTP a github")
+	content, err := sc.fetch(url)
+	if err != nil {
+		l.Error().Err(err).Msg("No se pudo acceder a github en intentos configurados! Saltando página...")
+		return nil, err
+	}
+	l.Trace().Msg("Usando expresión regular para encontrar artículo")
+	articles := re.article.FindAll(content, -1)
+
+	l.Trace().Msg("Procesando artículos")
+	var repos []interestRepo
+	for _, article := range articles {
+		l.Trace().Msgf("Buscar tiempo %v", re.sort.label)
+		timehtml := re.findTime(article)
+		timebyte := re.timestamp.Find(timehtml)
+		timestr := strings.ReplaceAll(string(timebyte), "\"", "")
+		if timestr == "" {
+			l.Trace().Msg("Saltando articulo sin tiempo (no es repositorio)")
+			continue
+		}
+		updtime, err := time.Parse(time.RFC3339, timestr)
+		if err != nil {
+			l.Error().Err(err).Msg("Error leyendo tiempo, saltando artículo.")
+			sc.parseFailure("interest")
+			continue
+		}
+
+		l.Trace().Msg("Buscar nombre del repositorio")
+		repo := interestRepo{Timestamp: updtime}
+		if name := re.reponame.FindSubmatch(re.title.Find(article)); name != nil {
+			repo.Name = strings.ToLower(string(name[1]))
+		}
+		l.Trace().Msg("Buscar lenguaje principal")
+		if lang := re.language.FindSubmatch(article); lang != nil {
+			repo.Language = sc.alias(strings.TrimSpace(string(lang[1])))
+		}
+
+		l.Trace().Msg("Usando expresión regular encontrar tags")
+		for _, tag := range re.tag.FindAll(article, -1) {
+			l.Trace().Msg("Procesando tag")
+			tag = re.tagbeg.ReplaceAll(tag, []byte{})
+			tag = re.tagfin.ReplaceAll(tag, []byte{})
+			l.Trace().Msg("Cortando todo menos texto")
+			repo.Tags = append(repo.Tags, strings.TrimSpace(string(tag)))
+		}
+		repos = append(repos, repo)
+	}
+	return repos, nil
+}
+
+// El orden se fuerza sobre el parámetro s, así funcionan también formatos que ya traen s=updated
+func (sc *Scraper) interestURL(page int, order interestSort) (string, error) {
+	u, err := url.Parse(fmt.Sprintf(sc.Config.Githubinterestformat, strings.ToLower(sc.Config.Interest), page))
+	if err != nil {
+		return "", err
+	}
+	query := u.Query()
+	query.Set("s", order.query)
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// Fecha precedida por la etiqueta del orden, o la primera fecha del artículo si ninguna la tiene
+func (re *interestRegexps) findTime(article []byte) []byte {
+	matches := re.timehtml.FindAllIndex(article, -1)
+	if matches == nil {
+		return nil
+	}
+	for _, match := range matches {
+		before := article[:match[0]]
+		if len(before) > 40 {
+			before = before[len(before)-40:]
+		}
+		if bytes.Contains(before, []byte(re.sort.label)) {
+			return article[match[0]:match[1]]
+		}
+	}
+	return article[matches[0][0]:matches[0][1]]
+}
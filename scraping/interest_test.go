@@ -0,0 +1,91 @@
+package scraping
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Página de un topic recortada. github muestra solo la fecha de actualización incluso al ordenar por
+// creación; la fecha de creación aparece en algunos artículos.
+func topicServer(t *testing.T) *httptest.Server {
+	page, err := os.ReadFile("testdata/topic.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") != "1" {
+			return
+		}
+		w.Write(page)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestScrapeInterestPageSorts(t *testing.T) {
+	server := topicServer(t)
+	tests := []struct {
+		sort  string
+		times []string
+	}{
+		{"updated", []string{"2024-01-10T08:00:00Z", "2024-01-09T08:00:00Z"}},
+		{"stars", []string{"2024-01-10T08:00:00Z", "2024-01-09T08:00:00Z"}},
+		{"forks", []string{"2024-01-10T08:00:00Z", "2024-01-09T08:00:00Z"}},
+		// Sin fecha de creación se usa la de actualización en lugar de descartar el artículo
+		{"created", []string{"2024-01-10T08:00:00Z", "2023-06-01T12:00:00Z"}},
+	}
+	for _, test := range tests {
+		t.Run(test.sort, func(t *testing.T) {
+			config := GetDefaultScraperConfig(zerolog.Nop())
+			config.Githubinterestformat = server.URL + "/topics/%v?page=%v"
+			sc := &Scraper{Config: &config, Logger: zerolog.Nop()}
+			re := newInterestRegexps(interestSorts[test.sort])
+
+			repos, err := sc.scrapeInterestPage(1, &re)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(repos) != len(test.times) {
+				t.Fatalf("got %v repos, want %v", len(repos), len(test.times))
+			}
+			for i, repo := range repos {
+				if got := repo.Timestamp.Format(time.RFC3339); got != test.times[i] {
+					t.Errorf("repo %v: time = %v, want %v", repo.Name, got, test.times[i])
+				}
+			}
+			if repos[1].Name != "other/api-server" || repos[1].Language != "Go" || len(repos[1].Tags) != 2 || repos[1].Tags[0] != "Golang" {
+				t.Errorf("unexpected repo %+v", repos[1])
+			}
+		})
+	}
+}
+
+func TestScrapeInterest(t *testing.T) {
+	server := topicServer(t)
+	for sort := range interestSorts {
+		t.Run(sort, func(t *testing.T) {
+			config := GetDefaultScraperConfig(zerolog.Nop())
+			config.Githubinterestformat = server.URL + "/topics/%v?page=%v"
+			config.InterestSort = sort
+			config.InterestMaxAgeDays = 0
+			config.AdaptiveMaxPages = 5
+			sc := &Scraper{Config: &config, Logger: zerolog.Nop()}
+
+			result, err := sc.ScrapeInterest()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Report.RecentRepositories != 2 || result.Report.StopReason != StopLastPage || result.Report.StopPage != 2 {
+				t.Errorf("unexpected report %+v", result.Report)
+			}
+			if result.Topics["go"] != 2 || result.Topics["cli"] != 1 || result.Topics["api"] != 1 {
+				t.Errorf("unexpected topics %v", result.Topics)
+			}
+		})
+	}
+}
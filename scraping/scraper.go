@@ -2,14 +2,12 @@ package scraping
 
 import (
 	"fmt"
-	"io"
-	"net/http"
 	"regexp"
 	"strconv"
 	"strings"
 	"sync"
-	"time"
 	"webscraping/common"
+	"webscraping/metrics"
 
 	"github.com/rs/zerolog"
 )
@@ -17,6 +15,17 @@ import (
 type Scraper struct {
 	Config *Scraperconfig
 	Logger zerolog.Logger
+	// Opcional, registra consultas y errores de lectura
+	Metrics *metrics.Registry
+	// Consultas hechas, para el reporte
+	mutex   sync.Mutex
+	records []FetchRecord
+}
+
+type TiobeLanguage struct {
+	Rank     int
+	Language string
+	Rating   float64
 }
 
 type Scraperconfig struct {
@@ -28,6 +37,12 @@ type Scraperconfig struct {
 	Interest             string            `json:"interest" yaml:"interest"`
 	MaxParallel          int               `json:"max_parallel" yaml:"max_parallel"`
 	Githubinterestformat string            `json:"github_interest_format" yaml:"github_interest_format"`
+	InterestMaxAgeDays   int               `json:"interest_max_age_days" yaml:"interest_max_age_days"`
+	AdaptivePaging       bool              `json:"adaptive_paging" yaml:"adaptive_paging"`
+	AdaptiveMaxPages     int               `json:"adaptive_max_pages" yaml:"adaptive_max_pages"`
+	SequentialPaging     bool              `json:"sequential_paging" yaml:"sequential_paging"`
+	InterestSort         string            `json:"interest_sort" yaml:"interest_sort"`
+	Tags                 TagConfig         `json:"tags" yaml:"tags"`
 }
 
 func GetDefaultScraperConfig(logger zerolog.Logger) Scraperconfig {
@@ -41,45 +56,34 @@ func GetDefaultScraperConfig(logger zerolog.Logger) Scraperconfig {
 		MaxPagesInterest:     10,
 		Interest:             "sort",
 		MaxParallel:          5,
-		Githubinterestformat: "https://github.com/topics/%v?o=desc&page=%v",
+		Githubinterestformat: "https://github.com/topics/%v?o=desc&s=updated&page=%v",
+		InterestMaxAgeDays:   30,
+		AdaptivePaging:       true,
+		AdaptiveMaxPages:     100,
+		SequentialPaging:     false,
+		InterestSort:         "updated",
+		Tags:                 GetDefaultTagConfig(),
+	}
+}
+
+// Revisa el orden de interest y las expresiones regulares de tags
+func (config *Scraperconfig) Validate(logger zerolog.Logger) error {
+	if _, ok := interestSorts[strings.ToLower(config.InterestSort)]; !ok {
+		return common.NewConfigError("interest_sort", config.InterestSort)
 	}
+	_, err := NewTagNormalizer(config.Tags, logger)
+	return err
 }
 
-func (sc *Scraper) ScrapeTiobe() ([]string, error) {
+func (sc *Scraper) ScrapeTiobe() ([]TiobeLanguage, error) {
 	l := sc.Logger.With().Str("method", "ScraperTiobe").Logger()
 
 	l.Trace().Str("url", sc.Config.Tiobesiteformat).Msgf("Accediendo a tiobe.")
-	response, err := http.Get(sc.Config.Tiobesiteformat)
+	content, err := sc.fetch(sc.Config.Tiobesiteformat)
 	if err != nil {
-		l.Error().Err(err).Msg("No se pudo acceder a tiobe!")
+		l.Error().Err(err).Msg("No se pudo acceder a tiobe en los intentos configurados!")
 		return nil, err
 	}
-	if response.StatusCode != http.StatusOK {
-		for _, delay := range sc.Config.RetryDelaysMs {
-			l.Warn().Int("Error code", response.StatusCode).Int("Tiempo espera", delay).Msg("La página retorno un error. Reintentando...")
-			time.Sleep(time.Millisecond * time.Duration(delay))
-			response, err = http.Get(sc.Config.Tiobesiteformat)
-			if err != nil {
-				l.Error().Err(err).Msg("No se pudo acceder a tiobe!")
-				return nil, err
-			}
-			if response.StatusCode == http.StatusOK {
-				break
-			}
-		}
-	}
-	if response.StatusCode != http.StatusOK {
-		l.Error().Int("Código respuesta", response.StatusCode).Msg("No se pudo acceder en los intentos configurados!")
-		return nil, common.NewStatusCodeError(response.StatusCode)
-	}
-	l.Trace().Str("url", sc.Config.Tiobesiteformat).Msgf("Leyendo contenido a cadena")
-	content, err := io.ReadAll(response.Body)
-	if err != nil {
-		l.Error().Err(err).Msg("No se pudo leer todo el contenido!")
-		return nil, err
-	}
-	l.Trace().Str("url", sc.Config.Tiobesiteformat).Msgf("Cerrando lector")
-	response.Body.Close()
 
 	l.Trace().Msgf("Compilando la expresión regular para la tabla de los top 20")
 	rt := regexp.MustCompile(`<table.*id="top20".*>(.|\n)*?</table>`)
@@ -87,6 +91,7 @@ func (sc *Scraper) ScrapeTiobe() ([]string, error) {
 	content = rt.Find(content)
 	if content == nil {
 		err := common.NewParseError("top 20 table")
+		sc.parseFailure("tiobe")
 		l.Error().Err(err).Msg("No se encontró la tabla!")
 		return nil, err
 	}
@@ -97,6 +102,7 @@ func (sc *Scraper) ScrapeTiobe() ([]string, error) {
 	tabledata := rtd.FindAll(content, 140)
 	if content == nil {
 		err := common.NewParseError("table data")
+		sc.parseFailure("tiobe")
 		l.Error().Err(err).Msg("No se encontro contenido en la tabla!")
 		return nil, err
 	}
@@ -105,17 +111,29 @@ func (sc *Scraper) ScrapeTiobe() ([]string, error) {
 	rtdr := regexp.MustCompile("</?td>")
 	l.Trace().Msgf("Limpiando filas de contenido html")
 	var languages []string
-	for i := 4; i < 140; i += 7 {
+	var ratings []float64
+	for i := 4; i+1 < len(tabledata) && i < 140; i += 7 {
 		lang := string(rtdr.ReplaceAll(tabledata[i], []byte{}))
 		l.Trace().Msgf("Agregando lenguaje %v", lang)
 		languages = append(languages, lang)
+		ratingstr := strings.TrimSpace(strings.TrimSuffix(string(rtdr.ReplaceAll(tabledata[i+1], []byte{})), "%"))
+		rating, err := strconv.ParseFloat(ratingstr, 64)
+		if err != nil {
+			l.Warn().Err(err).Str("lang", lang).Msg("No se pudo leer el rating, usando 0")
+		}
+		ratings = append(ratings, rating)
 	}
 
 	l.Trace().Msgf("Pasar lista a función de alias")
 	languages = sc.aliasreplace(languages)
 
+	var ret []TiobeLanguage
+	for i, lang := range languages {
+		ret = append(ret, TiobeLanguage{Rank: i + 1, Language: lang, Rating: ratings[i]})
+	}
+
 	l.Trace().Msgf("EXIT")
-	return languages, nil
+	return ret, nil
 }
 
 func (sc *Scraper) aliasreplace(original []string) []string {
@@ -124,19 +142,24 @@ func (sc *Scraper) aliasreplace(original []string) []string {
 	l.Trace().Msg("Reemplazando lenguajes con sus alias")
 	var replaced []string
 	for _, lang := range original {
-		replaceLang := sc.Config.Aliases[lang]
-		if replaceLang != "" {
-			l.Trace().Msgf("Reemplazando %v con alias %v", lang, replaceLang)
-			replaced = append(replaced, replaceLang)
-		} else {
-			l.Trace().Msgf("%v hno tiene original, usando original", lang)
-			replaced = append(replaced, lang)
-		}
+		replaced = append(replaced, sc.alias(lang))
 	}
 	l.Trace().Msgf("EXIT")
 	return replaced
 }
 
+func (sc *Scraper) alias(lang string) string {
+	l := sc.Logger.With().Str("method", "alias").Logger()
+
+	replaceLang := sc.Config.Aliases[lang]
+	if replaceLang != "" {
+		l.Trace().Msgf("Reemplazando %v con alias %v", lang, replaceLang)
+		return replaceLang
+	}
+	l.Trace().Msgf("%v hno tiene original, usando original", lang)
+	return lang
+}
+
 func (sc *Scraper) ScrapeGithub(languages []string) (map[string]int32, error) {
 	l := sc.Logger.With().Str("method", "ScrapeGithub").Logger()
 
@@ -162,57 +185,20 @@ func (sc *Scraper) ScrapeGithub(languages []string) (map[string]int32, error) {
 			maxchannel <- struct{}{}
 			url := fmt.Sprintf(sc.Config.Githubsiteformat, lang)
 			l.Trace().Str("url", url).Msgf("Haciendo consulta HTTP a github")
-			response, err := http.Get(url)
-			if err != nil {
-				l.Error().Err(err).Msg("No se pudo acceder a Github, saltando...")
-				errMutex.Lock()
-				lastError = err
-				errMutex.Unlock()
-				<-maxchannel
-				return
-			}
-			if response.StatusCode != http.StatusOK {
-				for _, delay := range sc.Config.RetryDelaysMs {
-					l.Warn().Int("Código error", response.StatusCode).Int("Tiempo espera", delay).Msg("Se retorno un error. Reintentando después de tiempo espera...")
-					time.Sleep(time.Millisecond * time.Duration(delay))
-					response, err = http.Get(url)
-					if err != nil {
-						l.Error().Err(err).Msg("No se pudo acceder a github!")
-						errMutex.Lock()
-						lastError = err
-						errMutex.Unlock()
-						<-maxchannel
-						return
-					}
-					if response.StatusCode == http.StatusOK {
-						break
-					}
-				}
-			}
-			if response.StatusCode != http.StatusOK {
-				l.Error().Int("StatusCode", response.StatusCode).Msg("No se pudo acceder a github en intentos configurados!")
-				errMutex.Lock()
-				lastError = common.NewStatusCodeError(response.StatusCode)
-				errMutex.Unlock()
-				<-maxchannel
-				return
-			}
-			l.Trace().Msg("Leer todo el contenido a cadena")
-			content, err := io.ReadAll(response.Body)
+			content, err := sc.fetch(url)
 			if err != nil {
-				l.Error().Err(err).Msg("No se pudo leer todo, reintentando...")
+				l.Error().Err(err).Msg("No se pudo acceder a github en intentos configurados! Saltando...")
 				errMutex.Lock()
 				lastError = err
 				errMutex.Unlock()
 				<-maxchannel
 				return
 			}
-			l.Trace().Msg("Cerrando lector")
-			response.Body.Close()
 			l.Trace().Msg("Usando expresión regular de la línea de número")
 			content = rtopicLine.Find(content)
 			if content == nil {
 				err := common.NewParseError("topic line")
+				sc.parseFailure("github")
 				l.Error().Err(err).Msg("No se encontró lo buscado! Saltando...")
 				errMutex.Lock()
 				lastError = err
@@ -224,6 +210,7 @@ func (sc *Scraper) ScrapeGithub(languages []string) (map[string]int32, error) {
 			content = rtopicnumber.Find(content)
 			if content == nil {
 				err := common.NewParseError("topic number")
+				sc.parseFailure("github")
 				l.Error().Err(err).Msg("No se encontró el número! Saltando...")
 				errMutex.Lock()
 				lastError = err
@@ -235,6 +222,7 @@ func (sc *Scraper) ScrapeGithub(languages []string) (map[string]int32, error) {
 			num, err := strconv.ParseInt(strings.ReplaceAll(string(content), ",", ""), 10, 32)
 			if err != nil {
 				l.Error().Err(err).Msg("No se pudo convertir a número! Saltando topic...")
+				sc.parseFailure("github")
 				errMutex.Lock()
 				lastError = err
 				errMutex.Unlock()
@@ -251,132 +239,3 @@ func (sc *Scraper) ScrapeGithub(languages []string) (map[string]int32, error) {
 	close(maxchannel)
 	return ret, lastError
 }
-
-func (sc *Scraper) ScrapeInterest() (map[string]int, error) {
-	l := sc.Logger.With().Str("method", "ScrapeGithub").Logger()
-
-	l.Trace().Msgf("Preparando para scraping de github: %v", sc.Config.Interest)
-	topics := make(map[string]int)
-
-	l.Trace().Msgf("Preparando expresiónes regulares para tags: %v", sc.Config.Interest)
-	l.Trace().Msgf("Compilando expresiónes regulares...")
-	rarticle := regexp.MustCompile(`<article.*>(.|\n)*?</article>`)
-	rtimehtml := regexp.MustCompile(`<relative-time.*>(.|\n)*?</relative-time>`)
-	rtimestamp := regexp.MustCompile(`\d\d\d\d-\d\d-\d\dT\d\d:\d\d:\d\dZ`)
-	rtag := regexp.MustCompile(`<a.*topic-tag topic-tag.*>(.|\n)*?</a>`)
-	rtagbeg := regexp.MustCompile(`<a.*topic-tag topic-tag.*>`)
-	rtagfin := regexp.MustCompile(`</a>`)
-
-	l.Trace().Msgf("Leer tiempo referencia")
-	now := time.Now()
-
-	var lastError error
-	var errMutex sync.Mutex
-	var mapMutex sync.Mutex
-	var wg sync.WaitGroup
-	maxchannel := make(chan struct{}, sc.Config.MaxParallel)
-
-	// Debemos empezar en pagina 1, porque si no github en pagina 0 y 1 retorna el mismo contenido
-	for i := 1; i <= sc.Config.MaxPagesInterest; i++ {
-		wg.Add(1)
-		page := i
-
-		go func() {
-			defer wg.Done()
-			// Contar, bloquea si se estan ejecutando ya MaxParallel rutinas
-			maxchannel <- struct{}{}
-
-			url := fmt.Sprintf(sc.Config.Githubinterestformat, strings.ToLower(sc.Config.Interest), page)
-			l.Trace().Str("url\n", url).Msgf("Haciendo consulta HTTP a github")
-			response, err := http.Get(url)
-			if err != nil {
-				l.Error().Err(err).Msg("No se pudo acceder a github! Saltando tag...")
-				errMutex.Lock()
-				lastError = err
-				errMutex.Unlock()
-				<-maxchannel
-				return
-			}
-			if response.StatusCode != http.StatusOK {
-				for _, delay := range sc.Config.RetryDelaysMs {
-					l.Warn().Int("Error code", response.StatusCode).Int("Delay", delay).Msg("Página retorno error. Reintentando en...")
-					time.Sleep(time.Millisecond * time.Duration(delay))
-					response, err = http.Get(url)
-					if err != nil {
-						l.Error().Err(err).Msg("No se pudo acceder a github!")
-						errMutex.Lock()
-						lastError = err
-						errMutex.Unlock()
-						<-maxchannel
-						return
-					}
-					if response.StatusCode == http.StatusOK {
-						break
-					}
-				}
-			}
-			if response.StatusCode != http.StatusOK {
-				l.Error().Int("StatusCode", response.StatusCode).Msg("No se pudo acceder a github en intentos configurados!")
-				errMutex.Lock()
-				lastError = common.NewStatusCodeError(response.StatusCode)
-				errMutex.Unlock()
-				<-maxchannel
-				return
-			}
-			l.Trace().Msg("Leyendo todo el contenido a cadena")
-			content, err := io.ReadAll(response.Body)
-			if err != nil {
-				l.Error().Err(err).Msg("No se pudo leer todo! Saltando topic...")
-				errMutex.Lock()
-				lastError = err
-				errMutex.Unlock()
-				<-maxchannel
-				return
-			}
-			l.Trace().Msg("Cerrando lector")
-			response.Body.Close()
-			l.Trace().Msg("Usando expresión regular para encontrar artículo")
-			articles := rarticle.FindAll(content, -1)
-
-			l.Trace().Msg("Procesando artículos")
-			for _, article := range articles {
-				l.Trace().Msg("Buscar tiempo")
-				timehtml := rtimehtml.Find(article)
-				timebyte := rtimestamp.Find(timehtml)
-				timestr := strings.ReplaceAll(string(timebyte), "\"", "")
-				updtime, err := time.Parse(time.RFC3339, timestr)
-				if timestr == "" {
-					l.Trace().Msg("Saltando articulo sin tiempo (no es repositorio)")
-					continue
-				}
-				if err != nil {
-					l.Error().Err(err).Msg("Error leyendo tiempo, saltando página.")
-					continue
-				}
-				l.Trace().Msg("Calculando diferencia en tiempo")
-				if now.Sub(updtime) > time.Duration(30*24)*time.Hour {
-					l.Trace().Msg("Este artículo es de hace más de 30 días, saltando...")
-					continue
-				}
-				l.Trace().Msg("Diferencia menor a 30 días, procesando...")
-				l.Trace().Msg("Usando expresión regular encontrar tags")
-				tags := rtag.FindAll(article, -1)
-				for _, tag := range tags {
-					l.Trace().Msg("Procesando tag")
-					tag = rtagbeg.ReplaceAll(tag, []byte{})
-					tag = rtagfin.ReplaceAll(tag, []byte{})
-					tagstr := string(tag)
-					l.Trace().Msg("Cortando todo menos texto")
-					tagstr = strings.TrimSpace(tagstr)
-
-					mapMutex.Lock()
-					topics[tagstr] = topics[tagstr] + 1
-					mapMutex.Unlock()
-				}
-			}
-			<-maxchannel
-		}()
-	}
-	wg.Wait()
-	return topics, lastError
-}
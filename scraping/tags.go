@@ -0,0 +1,178 @@
+package scraping
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+// Razones por las que se descarta un tag
+const (
+	DropStopList = "lista_exclusion"
+	DropExclude  = "regex_exclusion"
+	DropInclude  = "no_incluido"
+)
+
+type TagConfig struct {
+	CaseFold bool              `json:"case_fold" yaml:"case_fold"`
+	Synonyms map[string]string `json:"synonyms" yaml:"synonyms"`
+	Include  []string          `json:"include" yaml:"include"`
+	Exclude  []string          `json:"exclude" yaml:"exclude"`
+	StopList []string          `json:"stop_list" yaml:"stop_list"`
+}
+
+type TagChange struct {
+	From   string
+	To     string
+	Reason string
+	Count  int
+}
+
+type TagNormalizer struct {
+	logger   zerolog.Logger
+	config   TagConfig
+	synonyms map[string]string
+	stoplist map[string]bool
+	include  []*regexp.Regexp
+	exclude  []*regexp.Regexp
+	merged   map[[2]string]int
+	dropped  map[[2]string]int
+}
+
+func GetDefaultTagConfig() TagConfig {
+	return TagConfig{
+		CaseFold: true,
+		Synonyms: map[string]string{"golang": "go", "js": "javascript", "machinelearning": "machine-learning", "py": "python"},
+		Include:  []string{},
+		Exclude:  []string{},
+		StopList: []string{"hacktoberfest", "awesome", "awesome-list"},
+	}
+}
+
+func NewTagNormalizer(config TagConfig, logger zerolog.Logger) (*TagNormalizer, error) {
+	l := logger.With().Str("function", "NewTagNormalizer").Logger()
+
+	norm := TagNormalizer{
+		logger:   logger.With().Str("struct", "TagNormalizer").Logger(),
+		config:   config,
+		synonyms: make(map[string]string),
+		stoplist: make(map[string]bool),
+		merged:   make(map[[2]string]int),
+		dropped:  make(map[[2]string]int),
+	}
+
+	l.Trace().Msg("Preparando sinónimos y lista de exclusión")
+	for from, to := range config.Synonyms {
+		norm.synonyms[norm.fold(from)] = norm.fold(to)
+	}
+	for _, tag := range config.StopList {
+		norm.stoplist[norm.fold(tag)] = true
+	}
+
+	l.Trace().Msg("Compilando expresiones regulares de tags")
+	for _, expr := range config.Include {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			l.Error().Err(err).Str("regex", expr).Msg("Expresión regular de inclusión inválida!")
+			return nil, err
+		}
+		norm.include = append(norm.include, re)
+	}
+	for _, expr := range config.Exclude {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			l.Error().Err(err).Str("regex", expr).Msg("Expresión regular de exclusión inválida!")
+			return nil, err
+		}
+		norm.exclude = append(norm.exclude, re)
+	}
+
+	l.Trace().Msg("EXIT")
+	return &norm, nil
+}
+
+func (norm *TagNormalizer) fold(tag string) string {
+	tag = strings.TrimSpace(tag)
+	if norm.config.CaseFold {
+		tag = strings.ToLower(tag)
+	}
+	return tag
+}
+
+// Retorna el tag normalizado y false si el tag debe descartarse
+func (norm *TagNormalizer) Normalize(tag string) (string, bool) {
+	l := norm.logger.With().Str("method", "Normalize").Logger()
+
+	tag = norm.fold(tag)
+	// Solo los sinónimos cuentan como unión, pasar a minúsculas no
+	if synonym, ok := norm.synonyms[tag]; ok && synonym != tag {
+		l.Trace().Msgf("Uniendo %v en %v", tag, synonym)
+		norm.merged[[2]string{tag, synonym}]++
+		tag = synonym
+	}
+
+	if norm.stoplist[tag] {
+		return norm.drop(tag, DropStopList)
+	}
+	for _, re := range norm.exclude {
+		if re.MatchString(tag) {
+			return norm.drop(tag, DropExclude)
+		}
+	}
+	if len(norm.include) > 0 {
+		included := false
+		for _, re := range norm.include {
+			if re.MatchString(tag) {
+				included = true
+				break
+			}
+		}
+		if !included {
+			return norm.drop(tag, DropInclude)
+		}
+	}
+	return tag, true
+}
+
+func (norm *TagNormalizer) drop(tag, reason string) (string, bool) {
+	norm.logger.Trace().Str("method", "drop").Msgf("Descartando %v: %v", tag, reason)
+	norm.dropped[[2]string{tag, reason}]++
+	return tag, false
+}
+
+func (norm *TagNormalizer) Merged() []TagChange {
+	var changes []TagChange
+	for key, count := range norm.merged {
+		changes = append(changes, TagChange{From: key[0], To: key[1], Count: count})
+	}
+	sortChanges(changes)
+	return changes
+}
+
+func (norm *TagNormalizer) Dropped() []TagChange {
+	var changes []TagChange
+	for key, count := range norm.dropped {
+		changes = append(changes, TagChange{From: key[0], Reason: key[1], Count: count})
+	}
+	sortChanges(changes)
+	return changes
+}
+
+func sortChanges(changes []TagChange) {
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Count != changes[j].Count {
+			return changes[i].Count > changes[j].Count
+		}
+		return changes[i].From < changes[j].From
+	})
+}
+
+func (change *TagChange) String() string {
+	if change.Reason != "" {
+		return fmt.Sprintf("%v (%v): %d", change.From, change.Reason, change.Count)
+	}
+	return fmt.Sprintf("%v -> %v: %d", change.From, change.To, change.Count)
+}
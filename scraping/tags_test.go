@@ -0,0 +1,72 @@
+package scraping
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name   string
+		config TagConfig
+		tag    string
+		want   string
+		ok     bool
+	}{
+		{"minúsculas", TagConfig{CaseFold: true}, " Go ", "go", true},
+		{"sin minúsculas", TagConfig{}, "Go", "Go", true},
+		{"sinónimo", TagConfig{CaseFold: true, Synonyms: map[string]string{"golang": "go"}}, "golang", "go", true},
+		{"sinónimo en mayúsculas", TagConfig{CaseFold: true, Synonyms: map[string]string{"Golang": "Go"}}, "GOLANG", "go", true},
+		{"sinónimo distingue mayúsculas", TagConfig{Synonyms: map[string]string{"golang": "go"}}, "Golang", "Golang", true},
+		{"lista de exclusión", TagConfig{CaseFold: true, StopList: []string{"Hacktoberfest"}}, "hacktoberfest", "hacktoberfest", false},
+		{"exclusión después del sinónimo", TagConfig{CaseFold: true, Synonyms: map[string]string{"hf": "hacktoberfest"}, StopList: []string{"hacktoberfest"}}, "HF", "hacktoberfest", false},
+		{"regex de exclusión", TagConfig{Exclude: []string{"^awesome"}}, "awesome-go", "awesome-go", false},
+		{"regex de inclusión", TagConfig{Include: []string{"^go", "api$"}}, "rest-api", "rest-api", true},
+		{"no incluido", TagConfig{Include: []string{"^go"}}, "rust", "rust", false},
+		{"exclusión antes que inclusión", TagConfig{Include: []string{"^go"}, Exclude: []string{"-old$"}}, "go-old", "go-old", false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			norm, err := NewTagNormalizer(test.config, zerolog.Nop())
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, ok := norm.Normalize(test.tag)
+			if got != test.want || ok != test.ok {
+				t.Errorf("Normalize(%q) = %q, %v, want %q, %v", test.tag, got, ok, test.want, test.ok)
+			}
+		})
+	}
+}
+
+func TestNormalizeReport(t *testing.T) {
+	config := GetDefaultTagConfig()
+	config.Exclude = []string{"^x-"}
+	norm, err := NewTagNormalizer(config, zerolog.Nop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tag := range []string{"Go", "GO", "golang", "Golang", "JS", "Hacktoberfest", "x-test", "x-demo", "python"} {
+		norm.Normalize(tag)
+	}
+
+	// Pasar a minúsculas no es una unión
+	merged := fmt.Sprint(norm.Merged())
+	if want := "[{golang go  2} {js javascript  1}]"; merged != want {
+		t.Errorf("merged = %v, want %v", merged, want)
+	}
+	dropped := fmt.Sprint(norm.Dropped())
+	if want := "[{hacktoberfest  lista_exclusion 1} {x-demo  regex_exclusion 1} {x-test  regex_exclusion 1}]"; dropped != want {
+		t.Errorf("dropped = %v, want %v", dropped, want)
+	}
+}
+
+func TestNewTagNormalizerInvalid(t *testing.T) {
+	for _, config := range []TagConfig{{Include: []string{"("}}, {Exclude: []string{"[a-"}}} {
+		if _, err := NewTagNormalizer(config, zerolog.Nop()); err == nil {
+			t.Errorf("%+v: expected error", config)
+		}
+	}
+}
@@ -0,0 +1,359 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"webscraping/common"
+	"webscraping/dashboard"
+	"webscraping/export"
+	"webscraping/history"
+	"webscraping/metrics"
+
+	"github.com/rs/zerolog"
+)
+
+// Estados de la última corrida lanzada desde el servidor
+const (
+	StateIdle      = "idle"
+	StateRunning   = "running"
+	StateSucceeded = "succeeded"
+	StateFailed    = "failed"
+)
+
+// Con kind=all se corren todos los ejercicios
+const KindAll = "all"
+
+type Config struct {
+	Address string `json:"address" yaml:"address"`
+}
+
+// Un ejercicio que se puede correr en segundo plano, como app.RunLanguages
+type Job func() error
+
+type Status struct {
+	State    string    `json:"state"`
+	Kinds    []string  `json:"kinds,omitempty"`
+	Current  string    `json:"current,omitempty"`
+	Started  time.Time `json:"started"`
+	Finished time.Time `json:"finished"`
+	Errors   []string  `json:"errors,omitempty"`
+}
+
+type Server struct {
+	config    Config
+	dashboard dashboard.Config
+	history   *history.HistoryStore
+	// Gráficas que se pueden servir además de los archivos del reporte
+	charts []string
+	jobs   map[string]Job
+	// nil para no publicar /metrics
+	metrics *metrics.Registry
+	logger  zerolog.Logger
+	mutex   sync.Mutex
+	status  Status
+}
+
+func GetDefaultConfig() Config {
+	return Config{
+		Address: ":8080",
+	}
+}
+
+func NewServer(config Config, dash dashboard.Config, store *history.HistoryStore, charts []string, jobs map[string]Job, registry *metrics.Registry, logger zerolog.Logger) *Server {
+	var server Server
+	server.config = config
+	server.dashboard = dash
+	server.history = store
+	server.charts = charts
+	server.jobs = jobs
+	server.metrics = registry
+	server.logger = logger.With().Str("struct", "Server").Logger()
+	server.status = Status{State: StateIdle}
+	return &server
+}
+
+func (server *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", server.handleFile)
+	mux.HandleFunc("/api/results/", server.handleResults)
+	mux.HandleFunc("/api/history", server.handleHistory)
+	mux.HandleFunc("/api/history/", server.handleRun)
+	mux.HandleFunc("/api/scrape", server.handleScrape)
+	if server.metrics != nil {
+		mux.Handle("/metrics", server.metrics.Handler())
+	}
+	return mux
+}
+
+// Atiende hasta que se cancele el contexto, y espera a que terminen las consultas en curso
+func (server *Server) Serve(ctx context.Context) error {
+	l := server.logger.With().Str("method", "Serve").Logger()
+
+	httpserver := http.Server{Addr: server.config.Address, Handler: server.Handler()}
+	done := make(chan error, 1)
+	go func() {
+		<-ctx.Done()
+		l.Info().Msg("Apagando servidor...")
+		shutdown, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		done <- httpserver.Shutdown(shutdown)
+	}()
+
+	l.Info().Str("address", server.config.Address).Msg("Servidor escuchando")
+	err := httpserver.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		return <-done
+	}
+	return err
+}
+
+func (server *Server) Status() Status {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	status := server.status
+	status.Kinds = append([]string{}, server.status.Kinds...)
+	status.Errors = append([]string{}, server.status.Errors...)
+	return status
+}
+
+// Lanza los ejercicios en segundo plano. Retorna false si ya hay una corrida en curso.
+func (server *Server) Start(kind string) (bool, error) {
+	l := server.logger.With().Str("method", "Start").Str("kind", kind).Logger()
+
+	var kinds []string
+	if kind == KindAll {
+		for k := range server.jobs {
+			kinds = append(kinds, k)
+		}
+		sort.Strings(kinds)
+	} else if _, ok := server.jobs[kind]; ok {
+		kinds = []string{kind}
+	} else {
+		return false, common.NewConfigError("kind", kind)
+	}
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	if server.status.State == StateRunning {
+		l.Info().Msg("Ya hay una corrida en curso")
+		return false, nil
+	}
+	server.status = Status{State: StateRunning, Kinds: kinds, Started: time.Now()}
+	l.Info().Strs("kinds", kinds).Msg("Lanzando corrida en segundo plano")
+	go server.run(kinds)
+	return true, nil
+}
+
+func (server *Server) run(kinds []string) {
+	l := server.logger.With().Str("method", "run").Logger()
+
+	var failed []string
+	for _, kind := range kinds {
+		server.mutex.Lock()
+		server.status.Current = kind
+		server.mutex.Unlock()
+
+		if err := server.jobs[kind](); err != nil {
+			l.Error().Err(err).Str("kind", kind).Msg("Corrida fallida")
+			failed = append(failed, kind+": "+err.Error())
+		}
+	}
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	server.status.Current = ""
+	server.status.Finished = time.Now()
+	server.status.Errors = failed
+	server.status.State = StateSucceeded
+	if len(failed) > 0 {
+		server.status.State = StateFailed
+	}
+	l.Info().Str("state", server.status.State).Dur("duracion", server.status.Finished.Sub(server.status.Started)).Msg("Corrida terminada")
+}
+
+func (server *Server) handleFile(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	if r.URL.Path == "/" {
+		// Se redirige al reporte para que sus links relativos funcionen
+		http.Redirect(w, r, "/"+filepath.ToSlash(filepath.Clean(server.dashboard.HtmlFile)), http.StatusFound)
+		return
+	}
+	name := filepath.Clean(filepath.FromSlash(strings.TrimPrefix(r.URL.Path, "/")))
+	if !server.allowed(name) {
+		http.NotFound(w, r)
+		return
+	}
+	http.ServeFile(w, r, name)
+}
+
+// Archivos generados que se sirven desde dashboard.directory
+var servedExtensions = map[string]bool{".html": true, ".svg": true, ".png": true}
+
+// Solo se sirven el reporte, las gráficas y los archivos exportados, nunca la configuración
+func (server *Server) allowed(name string) bool {
+	if name == filepath.Clean(server.dashboard.HtmlFile) {
+		return true
+	}
+	if rel, err := filepath.Rel(filepath.Clean(server.dashboard.Directory), name); err == nil && !strings.HasPrefix(rel, "..") && servedExtensions[strings.ToLower(filepath.Ext(name))] {
+		return true
+	}
+	for _, chart := range server.charts {
+		if name == filepath.Clean(chart) {
+			return true
+		}
+	}
+	sections, err := dashboard.LoadSections(server.dashboard)
+	if err != nil {
+		return false
+	}
+	for _, section := range sections {
+		for _, file := range section.Exports {
+			if name == filepath.Clean(file) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// GET /api/results/<kind>: última tabla de resultados del ejercicio
+func (server *Server) handleResults(w http.ResponseWriter, r *http.Request) {
+	l := server.logger.With().Str("method", "handleResults").Logger()
+
+	if r.Method != http.MethodGet {
+		http.Error(w, "método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	kind := strings.TrimPrefix(r.URL.Path, "/api/results/")
+	if _, ok := server.jobs[kind]; !ok {
+		server.writeError(w, common.NewNotFoundError("resultados de "+kind))
+		return
+	}
+	section, err := dashboard.LoadSection(server.dashboard, kind)
+	if err != nil {
+		server.writeError(w, err)
+		return
+	}
+	if section.Table == nil {
+		server.writeError(w, common.NewNotFoundError("resultados de "+kind))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err = export.WriteJSON(w, section.Table); err != nil {
+		l.Error().Err(err).Msg("No se pudo escribir la respuesta")
+	}
+}
+
+// GET /api/history?kind=&from=&to=&language=&tag=: corridas del historial
+func (server *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	values := r.URL.Query()
+	query := history.Query{Kind: values.Get("kind"), Language: values.Get("language"), Tag: values.Get("tag")}
+	var err error
+	if from := values.Get("from"); from != "" {
+		if query.From, err = time.ParseInLocation("2006-01-02", from, time.Local); err != nil {
+			server.writeError(w, common.NewConfigError("from", from))
+			return
+		}
+	}
+	if to := values.Get("to"); to != "" {
+		if query.To, err = time.ParseInLocation("2006-01-02", to, time.Local); err != nil {
+			server.writeError(w, common.NewConfigError("to", to))
+			return
+		}
+		query.To = query.To.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	runs, err := server.history.Query(query)
+	if err != nil {
+		server.writeError(w, err)
+		return
+	}
+	if runs == nil {
+		runs = []history.Run{}
+	}
+	server.writeJSON(w, http.StatusOK, runs)
+}
+
+// GET /api/history/<ref>: una corrida por ID, o con ?kind= también latest, previous o una fecha
+func (server *Server) handleRun(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	ref := strings.TrimPrefix(r.URL.Path, "/api/history/")
+	var run *history.Run
+	var err error
+	if kind := r.URL.Query().Get("kind"); kind != "" {
+		run, err = server.history.Resolve(kind, ref)
+	} else {
+		run, err = server.history.Get(ref)
+	}
+	if err != nil {
+		server.writeError(w, err)
+		return
+	}
+	server.writeJSON(w, http.StatusOK, run)
+}
+
+// GET /api/scrape: estado de la última corrida. POST /api/scrape?kind=: lanza una corrida nueva.
+func (server *Server) handleScrape(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		server.writeJSON(w, http.StatusOK, server.Status())
+	case http.MethodPost:
+		kind := r.URL.Query().Get("kind")
+		if kind == "" {
+			kind = KindAll
+		}
+		started, err := server.Start(kind)
+		if err != nil {
+			server.writeError(w, err)
+			return
+		}
+		code := http.StatusAccepted
+		if !started {
+			code = http.StatusConflict
+		}
+		server.writeJSON(w, code, server.Status())
+	default:
+		http.Error(w, "método no permitido", http.StatusMethodNotAllowed)
+	}
+}
+
+func (server *Server) writeJSON(w http.ResponseWriter, code int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(value); err != nil {
+		server.logger.Error().Err(err).Str("method", "writeJSON").Msg("No se pudo escribir la respuesta")
+	}
+}
+
+func (server *Server) writeError(w http.ResponseWriter, err error) {
+	code := http.StatusInternalServerError
+	var notfound *common.NotFoundError
+	var config *common.ConfigError
+	switch {
+	case errors.As(err, &notfound):
+		code = http.StatusNotFound
+	case errors.As(err, &config):
+		code = http.StatusBadRequest
+	default:
+		server.logger.Error().Err(err).Str("method", "writeError").Msg("Error atendiendo consulta")
+	}
+	server.writeJSON(w, code, map[string]string{"error": err.Error()})
+}
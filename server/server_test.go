@@ -0,0 +1,48 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"webscraping/dashboard"
+
+	"github.com/rs/zerolog"
+)
+
+func TestAllowed(t *testing.T) {
+	dash := dashboard.Config{HtmlFile: "reporte.html", Directory: "reporte"}
+	server := NewServer(Config{}, dash, nil, []string{"grafo.html", "grafo.svg"}, nil, nil, zerolog.Nop())
+	tests := map[string]bool{
+		"reporte.html":          true,
+		"grafo.html":            true,
+		"grafo.svg":             true,
+		"reporte/languages.svg": true,
+		"reporte/tags.html":     true,
+		"reporte/tags.PNG":      true,
+		"reporte/tags.json":     false,
+		"reporte/runs.jsonl":    false,
+		"reporte/datos.csv":     false,
+		"reporte/app.config":    false,
+		"app.config":            false,
+		"historial/runs.jsonl":  false,
+		"webscraping.lock":      false,
+		"../reporte.html":       false,
+	}
+	for name, want := range tests {
+		if got := server.allowed(name); got != want {
+			t.Errorf("allowed(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestHandleFileTraversal(t *testing.T) {
+	dash := dashboard.Config{HtmlFile: "reporte.html", Directory: "reporte"}
+	server := NewServer(Config{}, dash, nil, nil, nil, nil, zerolog.Nop())
+	for _, path := range []string{"/reporte/../resource/config/app.config", "/reporte/%2e%2e/go.mod"} {
+		w := httptest.NewRecorder()
+		server.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code == http.StatusOK {
+			t.Errorf("%v: got status %v", path, w.Code)
+		}
+	}
+}
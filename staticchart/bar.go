@@ -0,0 +1,69 @@
+package staticchart
+
+import "math"
+
+type BarChart struct {
+	Title    string
+	Subtitle string
+	XLabel   string
+	YLabel   string
+	Labels   []string
+	Values   []float64
+}
+
+func (chart *BarChart) draw(c canvas, width, height int) {
+	const top = 70.0
+	right := float64(width) - 20
+
+	min, max := 0.0, 0.0
+	var labelwidth float64
+	for i, value := range chart.Values {
+		min = math.Min(min, value)
+		max = math.Max(max, value)
+		labelwidth = math.Max(labelwidth, textWidth(chart.Labels[i], 11))
+	}
+
+	// Si los nombres no entran debajo de cada barra se escriben verticales
+	slot := (right - 100) / math.Max(1, float64(len(chart.Values)))
+	vertical := labelwidth > slot-4
+	margin := 30.0
+	if vertical {
+		margin = math.Min(labelwidth, float64(height)/3) + 16
+	}
+	if chart.XLabel != "" {
+		margin += 20
+		c.text((right+100)/2, float64(height)-10, chart.XLabel, 12, anchorMiddle, "#666666", false)
+	}
+	bottom := float64(height) - margin
+
+	a := niceAxis(min, max)
+	left := drawFrame(c, width, chart.Title, chart.Subtitle, a, top, bottom, chart.YLabel)
+	slot = (right - left) / math.Max(1, float64(len(chart.Values)))
+	zero := a.y(0, top, bottom)
+	c.line(left, zero, right, zero, 1, "#6e7079", false)
+
+	for i, value := range chart.Values {
+		x := left + float64(i)*slot
+		y := a.y(value, top, bottom)
+		c.rect(x+slot*0.15, math.Min(y, zero), slot*0.7, math.Abs(zero-y), palette[0])
+		label := fitText(chart.Labels[i], 11, slot-4)
+		if vertical {
+			label = fitText(chart.Labels[i], 11, margin-16)
+			c.text(x+slot/2+4, bottom+8, label, 11, anchorEnd, "#6e7079", true)
+		} else {
+			c.text(x+slot/2, bottom+16, label, 11, anchorMiddle, "#6e7079", false)
+		}
+	}
+}
+
+// Corta el texto para que no pase del ancho dado
+func fitText(s string, size, width float64) string {
+	runes := []rune(s)
+	for len(runes) > 1 && textWidth(string(runes), size) > width {
+		runes = runes[:len(runes)-1]
+	}
+	if len(runes) < len([]rune(s)) && len(runes) > 1 {
+		runes[len(runes)-1] = '.'
+	}
+	return string(runes)
+}
@@ -0,0 +1,184 @@
+package staticchart
+
+import (
+	"encoding/xml"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Alineación horizontal del texto respecto a la coordenada x
+const (
+	anchorStart  = "start"
+	anchorMiddle = "middle"
+	anchorEnd    = "end"
+)
+
+// Superficie de dibujo común a svg y png. Las coordenadas están en pixeles y el y del texto es su línea base.
+type canvas interface {
+	rect(x, y, w, h float64, fill string)
+	line(x1, y1, x2, y2, width float64, stroke string, dashed bool)
+	polyline(points [][2]float64, width float64, stroke string, dashed bool)
+	// Con vertical el texto se escribe de abajo hacia arriba empezando en (x, y)
+	text(x, y float64, s string, size float64, anchor, fill string, vertical bool)
+	encode(w io.Writer) error
+}
+
+// Ancho aproximado del texto, para calcular márgenes
+func textWidth(s string, size float64) float64 {
+	return float64(len([]rune(s))) * size * 0.6
+}
+
+type svgCanvas struct {
+	width, height int
+	sb            strings.Builder
+}
+
+func newSVGCanvas(width, height int) *svgCanvas {
+	c := svgCanvas{width: width, height: height}
+	c.sb.WriteString(fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif">`+"\n", width, height, width, height))
+	c.rect(0, 0, float64(width), float64(height), "#ffffff")
+	return &c
+}
+
+func (c *svgCanvas) rect(x, y, w, h float64, fill string) {
+	c.sb.WriteString(fmt.Sprintf(`<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%v"/>`+"\n", x, y, w, h, fill))
+}
+
+func dash(dashed bool) string {
+	if dashed {
+		return ` stroke-dasharray="6 4"`
+	}
+	return ""
+}
+
+func (c *svgCanvas) line(x1, y1, x2, y2, width float64, stroke string, dashed bool) {
+	c.sb.WriteString(fmt.Sprintf(`<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="%v" stroke-width="%.1f"%v/>`+"\n", x1, y1, x2, y2, stroke, width, dash(dashed)))
+}
+
+func (c *svgCanvas) polyline(points [][2]float64, width float64, stroke string, dashed bool) {
+	coords := make([]string, 0, len(points))
+	for _, p := range points {
+		coords = append(coords, fmt.Sprintf("%.1f,%.1f", p[0], p[1]))
+	}
+	c.sb.WriteString(fmt.Sprintf(`<polyline points="%v" fill="none" stroke="%v" stroke-width="%.1f"%v/>`+"\n", strings.Join(coords, " "), stroke, width, dash(dashed)))
+}
+
+func (c *svgCanvas) text(x, y float64, s string, size float64, anchor, fill string, vertical bool) {
+	var escaped strings.Builder
+	xml.EscapeText(&escaped, []byte(s))
+	transform := ""
+	if vertical {
+		transform = fmt.Sprintf(` transform="rotate(-90 %.1f %.1f)"`, x, y)
+	}
+	c.sb.WriteString(fmt.Sprintf(`<text x="%.1f" y="%.1f" font-size="%.1f" text-anchor="%v" fill="%v"%v>%v</text>`+"\n", x, y, size, anchor, fill, transform, escaped.String()))
+}
+
+func (c *svgCanvas) encode(w io.Writer) error {
+	_, err := io.WriteString(w, c.sb.String()+"</svg>\n")
+	return err
+}
+
+type pngCanvas struct {
+	img *image.RGBA
+}
+
+func newPNGCanvas(width, height int) *pngCanvas {
+	c := pngCanvas{img: image.NewRGBA(image.Rect(0, 0, width, height))}
+	c.rect(0, 0, float64(width), float64(height), "#ffffff")
+	return &c
+}
+
+// Convierte colores de la forma #rrggbb
+func parseColor(s string) color.RGBA {
+	value, err := strconv.ParseUint(strings.TrimPrefix(s, "#"), 16, 32)
+	if err != nil || len(s) != 7 {
+		return color.RGBA{A: 255}
+	}
+	return color.RGBA{R: uint8(value >> 16), G: uint8(value >> 8), B: uint8(value), A: 255}
+}
+
+func (c *pngCanvas) fill(x0, y0, x1, y1 int, col color.RGBA) {
+	bounds := c.img.Bounds()
+	for y := y0; y < y1; y++ {
+		for x := x0; x < x1; x++ {
+			if image.Pt(x, y).In(bounds) {
+				c.img.SetRGBA(x, y, col)
+			}
+		}
+	}
+}
+
+func (c *pngCanvas) rect(x, y, w, h float64, fill string) {
+	c.fill(int(math.Round(x)), int(math.Round(y)), int(math.Round(x+w)), int(math.Round(y+h)), parseColor(fill))
+}
+
+func (c *pngCanvas) line(x1, y1, x2, y2, width float64, stroke string, dashed bool) {
+	c.polyline([][2]float64{{x1, y1}, {x2, y2}}, width, stroke, dashed)
+}
+
+// Recorre cada segmento en pasos de un pixel dibujando un cuadrado del ancho de la línea
+func (c *pngCanvas) polyline(points [][2]float64, width float64, stroke string, dashed bool) {
+	col := parseColor(stroke)
+	half := int(math.Max(width/2, 0.5))
+	var walked float64
+	for i := 1; i < len(points); i++ {
+		dx := points[i][0] - points[i-1][0]
+		dy := points[i][1] - points[i-1][1]
+		steps := math.Max(math.Abs(dx), math.Abs(dy))
+		for s := 0.0; s <= steps; s++ {
+			walked++
+			if dashed && math.Mod(walked, 10) >= 6 {
+				continue
+			}
+			t := 0.0
+			if steps > 0 {
+				t = s / steps
+			}
+			x := int(math.Round(points[i-1][0] + dx*t))
+			y := int(math.Round(points[i-1][1] + dy*t))
+			c.fill(x-half+1, y-half+1, x+half, y+half, col)
+		}
+	}
+}
+
+func (c *pngCanvas) text(x, y float64, s string, size float64, anchor, fill string, vertical bool) {
+	col := parseColor(fill)
+	scale := int(math.Max(1, math.Round(size/9)))
+	runes := []rune(accents.Replace(s))
+	length := len(runes) * (glyphWidth + 1) * scale
+	offset := 0
+	switch anchor {
+	case anchorMiddle:
+		offset = length / 2
+	case anchorEnd:
+		offset = length
+	}
+	for i, r := range runes {
+		g := glyph(r)
+		for row := 0; row < glyphHeight; row++ {
+			for column := 0; column < glyphWidth; column++ {
+				if g[row][column] != '#' {
+					continue
+				}
+				// Posición dentro del texto (a lo largo) y sobre la línea base (de alto)
+				along := i*(glyphWidth+1)*scale + column*scale - offset
+				up := (glyphHeight - row) * scale
+				px, py := int(x)+along, int(y)-up
+				if vertical {
+					px, py = int(x)-up, int(y)-along-scale
+				}
+				c.fill(px, py, px+scale, py+scale, col)
+			}
+		}
+	}
+}
+
+func (c *pngCanvas) encode(w io.Writer) error {
+	return png.Encode(w, c.img)
+}
@@ -0,0 +1,119 @@
+package staticchart
+
+import "math"
+
+// Serie de una gráfica de líneas, con un valor por etiqueta. Los nil son valores faltantes y se saltan.
+type Series struct {
+	Name   string
+	Values []*float64
+	Dashed bool
+	// Vacío para tomar el siguiente color de la paleta
+	Color string
+}
+
+type LineChart struct {
+	Title    string
+	Subtitle string
+	XLabel   string
+	YLabel   string
+	Labels   []string
+	Series   []Series
+}
+
+func (chart *LineChart) colors() []string {
+	colors := make([]string, len(chart.Series))
+	next := 0
+	for i, series := range chart.Series {
+		colors[i] = series.Color
+		if colors[i] == "" {
+			colors[i] = palette[next%len(palette)]
+			next++
+		}
+	}
+	return colors
+}
+
+// Leyenda debajo del subtítulo, solo con las series sólidas. Retorna dónde empieza el área de datos.
+func (chart *LineChart) drawLegend(c canvas, width int, colors []string) float64 {
+	x, y := 20.0, 66.0
+	for i, series := range chart.Series {
+		if series.Dashed {
+			continue
+		}
+		w := 20 + textWidth(series.Name, 11) + 16
+		if x+w > float64(width)-20 {
+			x = 20
+			y += 18
+		}
+		c.rect(x, y-8, 14, 8, colors[i])
+		c.text(x+18, y, series.Name, 11, anchorStart, "#333333", false)
+		x += w
+	}
+	return y + 16
+}
+
+func (chart *LineChart) draw(c canvas, width, height int) {
+	right := float64(width) - 20
+	colors := chart.colors()
+	top := chart.drawLegend(c, width, colors)
+
+	min, max := math.Inf(1), math.Inf(-1)
+	for _, series := range chart.Series {
+		for _, value := range series.Values {
+			if value != nil {
+				min = math.Min(min, *value)
+				max = math.Max(max, *value)
+			}
+		}
+	}
+	if math.IsInf(min, 0) {
+		min, max = 0, 1
+	}
+
+	margin := 30.0
+	if chart.XLabel != "" {
+		margin += 20
+		c.text(float64(width)/2, float64(height)-10, chart.XLabel, 12, anchorMiddle, "#666666", false)
+	}
+	bottom := float64(height) - margin
+
+	a := niceAxis(min, max)
+	left := drawFrame(c, width, chart.Title, chart.Subtitle, a, top, bottom, chart.YLabel)
+	c.line(left, bottom, right, bottom, 1, "#6e7079", false)
+
+	x := func(i int) float64 {
+		if len(chart.Labels) < 2 {
+			return (left + right) / 2
+		}
+		return left + float64(i)*(right-left)/float64(len(chart.Labels)-1)
+	}
+
+	// Se escribe una de cada every etiquetas para que no se superpongan
+	var labelwidth float64
+	for _, label := range chart.Labels {
+		labelwidth = math.Max(labelwidth, textWidth(label, 11))
+	}
+	every := int(math.Ceil((labelwidth + 12) * float64(len(chart.Labels)) / (right - left)))
+	if every < 1 {
+		every = 1
+	}
+	for i, label := range chart.Labels {
+		if i%every == 0 {
+			c.line(x(i), bottom, x(i), bottom+4, 1, "#6e7079", false)
+			c.text(x(i), bottom+16, label, 11, anchorMiddle, "#6e7079", false)
+		}
+	}
+
+	for i, series := range chart.Series {
+		var points [][2]float64
+		for j, value := range series.Values {
+			if value != nil && j < len(chart.Labels) {
+				points = append(points, [2]float64{x(j), a.y(*value, top, bottom)})
+			}
+		}
+		c.polyline(points, 2, colors[i], series.Dashed)
+		for _, p := range points {
+			c.rect(p[0]-2, p[1]-2, 4, 4, colors[i])
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package staticchart
+
+import (
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"webscraping/common"
+
+	"github.com/rs/zerolog"
+)
+
+// Formatos de salida soportados
+const (
+	FormatSVG = "svg"
+	FormatPNG = "png"
+)
+
+// Misma paleta que usa go-echarts por defecto, para que las gráficas estáticas se parezcan a las html
+var palette = []string{"#5470c6", "#91cc75", "#fac858", "#ee6666", "#73c0de", "#3ba272", "#fc8452", "#9a60b4", "#ea7ccc"}
+
+// Color de la paleta para la serie i
+func Color(i int) string {
+	return palette[i%len(palette)]
+}
+
+type Config struct {
+	Enabled bool     `json:"enabled" yaml:"enabled"`
+	Formats []string `json:"formats" yaml:"formats"`
+	Width   int      `json:"width" yaml:"width"`
+	Height  int      `json:"height" yaml:"height"`
+}
+
+func GetDefaultConfig() Config {
+	return Config{
+		Enabled: true,
+		Formats: []string{FormatSVG},
+		Width:   1200,
+		Height:  600,
+	}
+}
+
+// Revisa que todos los formatos sean soportados
+func (config *Config) Validate() error {
+	for _, format := range config.Formats {
+		if _, err := newCanvas(format, 1, 1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Una gráfica que se puede dibujar sobre cualquier canvas
+type Chart interface {
+	draw(c canvas, width, height int)
+}
+
+// Dibuja la gráfica en cada formato configurado. Los archivos se llaman como base con la extensión del formato.
+func Render(chart Chart, base string, config Config, logger zerolog.Logger) ([]string, error) {
+	l := logger.With().Str("function", "Render").Logger()
+
+	base = strings.TrimSuffix(base, filepath.Ext(base))
+	var files []string
+	for _, format := range config.Formats {
+		c, err := newCanvas(format, config.Width, config.Height)
+		if err != nil {
+			l.Error().Str("formato", format).Msg("Formato de gráfica estática no soportado! Use svg o png")
+			return files, err
+		}
+		chart.draw(c, config.Width, config.Height)
+
+		filename := base + "." + strings.ToLower(format)
+		l.Trace().Str("file", filename).Msg("Guardando gráfica estática")
+		if err := save(c, filename); err != nil {
+			l.Error().Err(err).Str("file", filename).Msg("No se pudo guardar gráfica estática")
+			return files, err
+		}
+		files = append(files, filename)
+	}
+	return files, nil
+}
+
+func save(c canvas, filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err = c.encode(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func newCanvas(format string, width, height int) (canvas, error) {
+	switch strings.ToLower(format) {
+	case FormatSVG:
+		return newSVGCanvas(width, height), nil
+	case FormatPNG:
+		return newPNGCanvas(width, height), nil
+	}
+	return nil, common.NewConfigError("static_charts.formats", format)
+}
+
+// Escribe la gráfica en un solo formato sobre w
+func Encode(chart Chart, format string, width, height int, w io.Writer) error {
+	c, err := newCanvas(format, width, height)
+	if err != nil {
+		return err
+	}
+	chart.draw(c, width, height)
+	return c.encode(w)
+}
+
+// Escala del eje de valores con límites y paso redondos
+type axis struct {
+	min, max, step float64
+}
+
+func niceAxis(min, max float64) axis {
+	if min > 0 {
+		min = 0
+	}
+	if max < 0 {
+		max = 0
+	}
+	if max == min {
+		max = min + 1
+	}
+	step := niceNumber((max - min) / 5)
+	return axis{
+		min:  math.Floor(min/step) * step,
+		max:  math.Ceil(max/step) * step,
+		step: step,
+	}
+}
+
+// Redondea hacia arriba a 1, 2, 5 o 10 por una potencia de 10
+func niceNumber(value float64) float64 {
+	exponent := math.Floor(math.Log10(value))
+	fraction := value / math.Pow(10, exponent)
+	switch {
+	case fraction <= 1:
+		fraction = 1
+	case fraction <= 2:
+		fraction = 2
+	case fraction <= 5:
+		fraction = 5
+	default:
+		fraction = 10
+	}
+	return fraction * math.Pow(10, exponent)
+}
+
+func (a axis) ticks() []float64 {
+	var ticks []float64
+	for v := a.min; v <= a.max+a.step/2; v += a.step {
+		ticks = append(ticks, v)
+	}
+	return ticks
+}
+
+// Posición y en pixeles de un valor dentro del área [top, bottom]
+func (a axis) y(value, top, bottom float64) float64 {
+	return bottom - (value-a.min)/(a.max-a.min)*(bottom-top)
+}
+
+func formatTick(value float64) string {
+	if math.Abs(value) >= 1000000 {
+		return trimFloat(value/1000000) + "M"
+	}
+	if math.Abs(value) >= 1000 {
+		return trimFloat(value/1000) + "k"
+	}
+	return trimFloat(value)
+}
+
+func trimFloat(value float64) string {
+	s := strings.TrimRight(strings.TrimRight(strconv.FormatFloat(value, 'f', 2, 64), "0"), ".")
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
+
+// Título, subtítulo y grilla con las marcas del eje de valores. Retorna el borde izquierdo del área de datos.
+func drawFrame(c canvas, width int, title, subtitle string, a axis, top, bottom float64, ylabel string) float64 {
+	c.text(20, 28, title, 20, anchorStart, "#333333", false)
+	c.text(20, 48, subtitle, 12, anchorStart, "#666666", false)
+
+	var labelwidth float64
+	for _, tick := range a.ticks() {
+		labelwidth = math.Max(labelwidth, textWidth(formatTick(tick), 11))
+	}
+	left := 30 + labelwidth
+	if ylabel != "" {
+		left += 16
+		c.text(20, (top+bottom)/2, ylabel, 12, anchorMiddle, "#666666", true)
+	}
+	for _, tick := range a.ticks() {
+		y := a.y(tick, top, bottom)
+		c.line(left, y, float64(width)-20, y, 1, "#e0e6f1", false)
+		c.text(left-6, y+4, formatTick(tick), 11, anchorEnd, "#6e7079", false)
+	}
+	return left
+}
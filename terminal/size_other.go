@@ -0,0 +1,11 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd
+// +build !linux,!darwin,!freebsd,!netbsd,!openbsd
+
+package terminal
+
+import "os"
+
+// Sin ioctl se usa COLUMNS o el ancho por defecto
+func columns(f *os.File) int {
+	return 0
+}
@@ -0,0 +1,246 @@
+package terminal
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+	"webscraping/common"
+)
+
+// Modos de salida. Con auto se usa rich solo si la salida es una terminal.
+const (
+	ModeAuto  = "auto"
+	ModeRich  = "rich"
+	ModePlain = "plain"
+)
+
+// Alineación de una columna
+const (
+	AlignLeft = iota
+	AlignRight
+)
+
+const (
+	defaultWidth = 80
+	minBarWidth  = 10
+	separator    = "  "
+)
+
+const (
+	ansiReset = "\x1b[0m"
+	ansiBold  = "\x1b[1m"
+	ansiDim   = "\x1b[2m"
+	ansiRed   = "\x1b[31m"
+	ansiCyan  = "\x1b[36m"
+)
+
+// Bloques de un octavo a un bloque completo
+var blocks = []rune{'▏', '▎', '▍', '▌', '▋', '▊', '▉', '█'}
+
+type Config struct {
+	Mode   string `json:"mode" yaml:"mode"`
+	Colors bool   `json:"colors" yaml:"colors"`
+	// 0 para usar el ancho de la terminal
+	Width int `json:"width" yaml:"width"`
+}
+
+type Column struct {
+	Name  string
+	Align int
+}
+
+type Table struct {
+	Title   string
+	Columns []Column
+	Rows    [][]string
+	// Valor de la barra de cada fila, vacío para no dibujar barras. Los negativos se dibujan en rojo.
+	Bars []float64
+}
+
+type Renderer struct {
+	out    io.Writer
+	rich   bool
+	colors bool
+	width  int
+}
+
+func GetDefaultConfig() Config {
+	return Config{
+		Mode:   ModeAuto,
+		Colors: true,
+		Width:  0,
+	}
+}
+
+func (config *Config) Validate() error {
+	if config.Mode != ModeAuto && config.Mode != ModeRich && config.Mode != ModePlain {
+		return common.NewConfigError("terminal.mode", config.Mode)
+	}
+	return nil
+}
+
+// Una terminal es un dispositivo de caracteres, los archivos y pipes no
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+func NewRenderer(out *os.File, config Config) (*Renderer, error) {
+	r := Renderer{out: out}
+	switch config.Mode {
+	case ModeAuto:
+		r.rich = IsTerminal(out)
+	case ModeRich:
+		r.rich = true
+	case ModePlain:
+		r.rich = false
+	default:
+		return nil, common.NewConfigError("terminal.mode", config.Mode)
+	}
+	// https://no-color.org
+	r.colors = r.rich && config.Colors && os.Getenv("NO_COLOR") == ""
+
+	r.width = config.Width
+	if r.width <= 0 {
+		r.width = columns(out)
+	}
+	if r.width <= 0 {
+		r.width, _ = strconv.Atoi(os.Getenv("COLUMNS"))
+	}
+	if r.width <= 0 {
+		r.width = defaultWidth
+	}
+	return &r, nil
+}
+
+func (r *Renderer) Render(table *Table) error {
+	_, err := io.WriteString(r.out, r.String(table))
+	return err
+}
+
+func (r *Renderer) paint(s, code string) string {
+	if !r.colors || s == "" {
+		return s
+	}
+	return code + s + ansiReset
+}
+
+func pad(s string, width, align int) string {
+	fill := strings.Repeat(" ", width-utf8.RuneCountInString(s))
+	if align == AlignRight {
+		return fill + s
+	}
+	return s + fill
+}
+
+func (r *Renderer) String(table *Table) string {
+	widths := make([]int, len(table.Columns))
+	for i, column := range table.Columns {
+		widths[i] = utf8.RuneCountInString(column.Name)
+	}
+	for _, row := range table.Rows {
+		for i, cell := range row {
+			if n := utf8.RuneCountInString(cell); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+	tablewidth := 0
+	for _, w := range widths {
+		tablewidth += w + len(separator)
+	}
+
+	// En modo plano no hay barras; si no queda lugar en la terminal tampoco
+	barwidth := r.width - tablewidth
+	bars := r.rich && len(table.Bars) == len(table.Rows) && barwidth >= minBarWidth
+	var maxbar float64
+	for _, value := range table.Bars {
+		maxbar = math.Max(maxbar, math.Abs(value))
+	}
+
+	var sb strings.Builder
+	if table.Title != "" {
+		sb.WriteString(r.paint(table.Title, ansiBold) + "\n")
+	}
+	header := make([]string, len(table.Columns))
+	for i, column := range table.Columns {
+		header[i] = pad(column.Name, widths[i], column.Align)
+	}
+	sb.WriteString(r.paint(strings.TrimRight(strings.Join(header, separator), " "), ansiBold) + "\n")
+	if r.rich {
+		rule := tablewidth - len(separator)
+		if bars {
+			rule = r.width
+		}
+		sb.WriteString(r.paint(strings.Repeat("─", rule), ansiDim) + "\n")
+	}
+
+	for i, row := range table.Rows {
+		cells := make([]string, len(row))
+		for j, cell := range row {
+			cells[j] = pad(cell, widths[j], table.Columns[j].Align)
+		}
+		if r.rich && len(cells) > 0 {
+			cells[0] = r.paint(cells[0], ansiDim)
+		}
+		line := strings.Join(cells, separator)
+		if bars {
+			line += separator + r.bar(table.Bars[i], maxbar, barwidth-len(separator))
+		}
+		sb.WriteString(strings.TrimRight(line, " ") + "\n")
+	}
+	return sb.String()
+}
+
+// Barra de largo proporcional a value/max, con resolución de un octavo de caracter
+func (r *Renderer) bar(value, max float64, width int) string {
+	if max == 0 || width <= 0 {
+		return ""
+	}
+	eighths := int(math.Round(math.Abs(value) / max * float64(width*8)))
+	s := strings.Repeat(string(blocks[7]), eighths/8)
+	if eighths%8 > 0 {
+		s += string(blocks[eighths%8-1])
+	}
+	if value < 0 {
+		if !r.colors {
+			// Sin colores los negativos se distinguen con sombreado
+			return strings.Repeat("░", utf8.RuneCountInString(s))
+		}
+		return r.paint(s, ansiRed)
+	}
+	return r.paint(s, ansiCyan)
+}
+
+// Formatea un número con separador de miles, para las columnas de cantidades
+func Count(n int64) string {
+	s := strconv.FormatInt(n, 10)
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+	var sb strings.Builder
+	for i, c := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			sb.WriteRune('.')
+		}
+		sb.WriteRune(c)
+	}
+	if negative {
+		return "-" + sb.String()
+	}
+	return sb.String()
+}
+
+// Formatea un decimal con la cantidad de dígitos dada
+func Float(value float64, digits int) string {
+	if math.IsNaN(value) {
+		return "-"
+	}
+	return fmt.Sprintf("%.*f", digits, value)
+}